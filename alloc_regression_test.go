@@ -4,6 +4,8 @@ import (
 	"io"
 	"math"
 	"testing"
+
+	"pkt.systems/pslog/internal/race"
 )
 
 // Regression: hot path logging should allocate 0 bytes for all emitter variants
@@ -102,3 +104,36 @@ func TestJSONNonFiniteFloatAllocateZero(t *testing.T) {
 		}
 	}
 }
+
+// Regression: the caller field is resolved through the per-PC cache and must
+// not allocate in steady state, whatever the caller mode.
+func TestLoggersWithCallerAllocateZero(t *testing.T) {
+	if race.Enabled {
+		// The caller pair is appended to the pooled line writer's scratch
+		// keyvals, which start empty whenever -race drops the writer.
+		t.Skip("sync.Pool drops pooled line writers under -race")
+	}
+	keyvals := []any{"key", "value", "n", 123}
+
+	for _, mode := range []CallerMode{CallerModeFunction, CallerModePackage, CallerModeShortFile, CallerModeFullPath} {
+		for _, opts := range []Options{
+			{Mode: ModeConsole, NoColor: true},
+			{Mode: ModeConsole, ForceColor: true},
+			{Mode: ModeStructured, NoColor: true},
+			{Mode: ModeStructured, ForceColor: true},
+		} {
+			opts.DisableTimestamp = true
+			opts.CallerKeyval = true
+			opts.CallerMode = mode
+			logger := NewWithOptions(nil, io.Discard, opts)
+			logger.Info("warm", keyvals...)
+
+			allocs := testing.AllocsPerRun(1000, func() {
+				logger.Info("msg", keyvals...)
+			})
+			if allocs != 0 {
+				t.Fatalf("mode %d, %+v: expected 0 allocs/log, got %.2f", mode, opts, allocs)
+			}
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package pslog
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	defaultAsyncCapacity  = 1024
+	defaultAsyncBatchSize = 64 << 10
+)
+
+// AsyncPolicy selects what AsyncWriter does when its buffer is full.
+type AsyncPolicy int
+
+const (
+	// AsyncBlock makes the logging goroutine wait for free space.
+	AsyncBlock AsyncPolicy = iota
+	// AsyncDropNewest discards the incoming line.
+	AsyncDropNewest
+	// AsyncDropOldest discards the oldest buffered line to make room.
+	AsyncDropOldest
+)
+
+// AsyncOptions configures an AsyncWriter.
+type AsyncOptions struct {
+	// Capacity is the number of lines the buffer holds. Defaults to 1024.
+	Capacity int
+	// Policy selects the behaviour when the buffer is full. Lines at
+	// ErrorLevel and above are never dropped; they wait for space regardless
+	// of Policy.
+	Policy AsyncPolicy
+	// BatchSize caps the number of bytes handed to the destination in a
+	// single Write. Defaults to 64KiB; a line larger than BatchSize is
+	// written on its own.
+	BatchSize int
+}
+
+// AsyncWriterStats captures cumulative AsyncWriter counters.
+type AsyncWriterStats struct {
+	// Enqueued counts lines accepted into the buffer.
+	Enqueued uint64
+	// Written counts lines handed to the destination.
+	Written uint64
+	// Dropped counts lines discarded by the drop policies.
+	Dropped uint64
+	// Blocked counts writes that had to wait for free space.
+	Blocked uint64
+	// WriteErrors counts failed destination writes.
+	WriteErrors uint64
+}
+
+type asyncSlot struct {
+	level Level
+	buf   []byte
+}
+
+// AsyncWriter moves log output off the logging goroutine. Committed lines are
+// copied into a ring buffer and a background goroutine writes them to the
+// destination in batches. When a logger is built on an AsyncWriter, each line
+// carries its level so the drop policies can spare errors.
+//
+// Cancelling the logger's context or closing the logger closes the
+// AsyncWriter, even one the caller created, which drains the buffer before
+// closing the destination when pslog owns it. Call Flush to wait for buffered
+// lines without closing, and Close when the writer is no longer needed so the
+// drain goroutine exits.
+type AsyncWriter struct {
+	dst       io.Writer
+	policy    AsyncPolicy
+	batchSize int
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	slots    []asyncSlot
+	head     int
+	count    int
+	accepted uint64
+	done     uint64
+	progress chan struct{}
+	closed   bool
+	batch    []byte
+
+	finished  chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+
+	enqueued    atomic.Uint64
+	written     atomic.Uint64
+	dropped     atomic.Uint64
+	blocked     atomic.Uint64
+	writeErrors atomic.Uint64
+}
+
+// NewAsyncWriter returns an AsyncWriter draining into dst and starts its
+// background goroutine.
+//
+// Unlike other writers passed to a logger, the AsyncWriter is treated as part
+// of every logger built on it: Close or Fatal on any of those loggers, or
+// cancelling any of their contexts, drains and closes it for all of them.
+// Give each independently closed logger its own AsyncWriter. dst itself is
+// only closed when pslog opened it.
+func NewAsyncWriter(dst io.Writer, opts AsyncOptions) *AsyncWriter {
+	if dst == nil {
+		dst = io.Discard
+	}
+	capacity := opts.Capacity
+	if capacity <= 0 {
+		capacity = defaultAsyncCapacity
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultAsyncBatchSize
+	}
+	w := &AsyncWriter{
+		dst:       dst,
+		policy:    opts.Policy,
+		batchSize: batchSize,
+		slots:     make([]asyncSlot, capacity),
+		finished:  make(chan struct{}),
+	}
+	w.notEmpty = sync.NewCond(&w.mu)
+	w.notFull = sync.NewCond(&w.mu)
+	go w.drain()
+	return w
+}
+
+// Write buffers p as a single line. Lines written directly, rather than
+// through a logger, are treated as InfoLevel by the drop policies.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	return w.writeLevel(InfoLevel, p)
+}
+
+// commitLine hands the finished line in lw to the buffer, mirroring
+// lineWriter.commit for loggers built on an AsyncWriter.
+func (w *AsyncWriter) commitLine(level Level, lw *lineWriter) {
+	lw.lastLen = len(lw.buf)
+	if len(lw.buf) > 0 {
+		_, _ = w.writeLevel(level, lw.buf)
+	}
+	lw.buf = lw.buf[:0]
+}
+
+func (w *AsyncWriter) writeLevel(level Level, p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	droppable := level < ErrorLevel
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, os.ErrClosed
+	}
+	if w.count == len(w.slots) {
+		switch {
+		case w.policy == AsyncDropOldest && w.dropOldestLocked():
+		case droppable && w.policy != AsyncBlock:
+			w.mu.Unlock()
+			w.dropped.Add(1)
+			return len(p), nil
+		default:
+			w.blocked.Add(1)
+			for w.count == len(w.slots) && !w.closed {
+				w.notFull.Wait()
+			}
+			if w.closed {
+				w.mu.Unlock()
+				return 0, os.ErrClosed
+			}
+		}
+	}
+	slot := &w.slots[(w.head+w.count)%len(w.slots)]
+	slot.level = level
+	slot.buf = append(slot.buf[:0], p...)
+	w.count++
+	w.accepted++
+	w.enqueued.Add(1)
+	w.notEmpty.Signal()
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+// dropOldestLocked discards the oldest droppable line, shifting newer lines
+// towards the head. It reports false when every buffered line is an error.
+func (w *AsyncWriter) dropOldestLocked() bool {
+	n := len(w.slots)
+	victim := -1
+	for i := 0; i < w.count; i++ {
+		if w.slots[(w.head+i)%n].level < ErrorLevel {
+			victim = i
+			break
+		}
+	}
+	if victim < 0 {
+		return false
+	}
+	for i := victim; i > 0; i-- {
+		cur := (w.head + i) % n
+		prev := (w.head + i - 1) % n
+		w.slots[cur], w.slots[prev] = w.slots[prev], w.slots[cur]
+	}
+	w.slots[w.head].buf = w.slots[w.head].buf[:0]
+	w.head = (w.head + 1) % n
+	w.count--
+	w.done++
+	w.dropped.Add(1)
+	return true
+}
+
+func (w *AsyncWriter) drain() {
+	defer close(w.finished)
+	w.mu.Lock()
+	for {
+		for w.count == 0 && !w.closed {
+			w.notEmpty.Wait()
+		}
+		if w.count == 0 && w.closed {
+			w.signalProgressLocked()
+			w.mu.Unlock()
+			return
+		}
+		batch := w.batch[:0]
+		lines := 0
+		for w.count > 0 {
+			slot := &w.slots[w.head]
+			if lines > 0 && len(batch)+len(slot.buf) > w.batchSize {
+				break
+			}
+			batch = append(batch, slot.buf...)
+			slot.buf = slot.buf[:0]
+			w.head = (w.head + 1) % len(w.slots)
+			w.count--
+			lines++
+		}
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+
+		n, err := w.dst.Write(batch)
+		if err != nil || n != len(batch) {
+			w.writeErrors.Add(1)
+		}
+		w.written.Add(uint64(lines))
+
+		w.mu.Lock()
+		if cap(batch) <= 4*w.batchSize {
+			w.batch = batch[:0]
+		}
+		w.done += uint64(lines)
+		w.signalProgressLocked()
+	}
+}
+
+// signalProgressLocked wakes Flush callers. The channel only exists while
+// someone is waiting, so the drain loop does not allocate otherwise.
+func (w *AsyncWriter) signalProgressLocked() {
+	if w.progress != nil {
+		close(w.progress)
+		w.progress = nil
+	}
+}
+
+// Flush waits until every line accepted before the call has been written or
+// dropped, or until ctx is done.
+func (w *AsyncWriter) Flush(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	w.mu.Lock()
+	target := w.accepted
+	for w.done < target {
+		if w.progress == nil {
+			w.progress = make(chan struct{})
+		}
+		progress := w.progress
+		w.mu.Unlock()
+		select {
+		case <-progress:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		w.mu.Lock()
+	}
+	w.mu.Unlock()
+	return nil
+}
+
+// Close stops accepting lines, waits for the buffer to drain and closes the
+// destination when pslog owns it. It is safe to call more than once.
+func (w *AsyncWriter) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.notEmpty.Broadcast()
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+		<-w.finished
+		w.closeErr = closeOutput(w.dst)
+	})
+	return w.closeErr
+}
+
+func (w *AsyncWriter) pslogOwnedClose() error {
+	return w.Close()
+}
+
+// Stats returns cumulative counters.
+func (w *AsyncWriter) Stats() AsyncWriterStats {
+	if w == nil {
+		return AsyncWriterStats{}
+	}
+	return AsyncWriterStats{
+		Enqueued:    w.enqueued.Load(),
+		Written:     w.written.Load(),
+		Dropped:     w.dropped.Load(),
+		Blocked:     w.blocked.Load(),
+		WriteErrors: w.writeErrors.Load(),
+	}
+}
@@ -0,0 +1,222 @@
+package pslog_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pkt.systems/pslog"
+)
+
+// gatedWriter blocks every Write until release is closed and reports when the
+// first Write has started.
+type gatedWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	once    sync.Once
+	release chan struct{}
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	g.once.Do(func() { close(g.started) })
+	<-g.release
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Write(p)
+}
+
+func (g *gatedWriter) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.String()
+}
+
+func asyncTestLogger(w *pslog.AsyncWriter) pslog.Logger {
+	return pslog.NewWithOptions(nil, w, pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, NoColor: true})
+}
+
+func TestAsyncWriterDeliversInOrder(t *testing.T) {
+	var buf syncBuffer
+	aw := pslog.NewAsyncWriter(&buf, pslog.AsyncOptions{Capacity: 8, BatchSize: 32})
+	defer aw.Close()
+	logger := asyncTestLogger(aw)
+	for i := range 50 {
+		logger.Info("line", "n", i)
+	}
+	if err := aw.Flush(t.Context()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 50 {
+		t.Fatalf("expected 50 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if want := "INF line n=" + strconv.Itoa(i); line != want {
+			t.Fatalf("line %d: got %q want %q", i, line, want)
+		}
+	}
+	stats := aw.Stats()
+	if stats.Enqueued != 50 || stats.Written != 50 || stats.Dropped != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAsyncWriterDropNewestSparesErrors(t *testing.T) {
+	dst := newGatedWriter()
+	aw := pslog.NewAsyncWriter(dst, pslog.AsyncOptions{Capacity: 2, Policy: pslog.AsyncDropNewest})
+	defer aw.Close()
+	logger := asyncTestLogger(aw)
+
+	logger.Info("a")
+	<-dst.started
+	logger.Info("b")
+	logger.Info("c")
+	logger.Info("d")
+
+	done := make(chan struct{})
+	go func() {
+		logger.Error("e")
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(dst.release)
+	<-done
+	if err := aw.Flush(t.Context()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	if got, want := dst.String(), "INF a\nINF b\nINF c\nERR e\n"; got != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", got, want)
+	}
+	if stats := aw.Stats(); stats.Dropped != 1 || stats.Blocked != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAsyncWriterDropOldest(t *testing.T) {
+	dst := newGatedWriter()
+	aw := pslog.NewAsyncWriter(dst, pslog.AsyncOptions{Capacity: 2, Policy: pslog.AsyncDropOldest})
+	defer aw.Close()
+	logger := asyncTestLogger(aw)
+
+	logger.Info("a")
+	<-dst.started
+	logger.Info("b")
+	logger.Error("c")
+	logger.Info("d")
+	logger.Error("e")
+	close(dst.release)
+	if err := aw.Flush(t.Context()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	if got, want := dst.String(), "INF a\nERR c\nERR e\n"; got != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", got, want)
+	}
+	if stats := aw.Stats(); stats.Dropped != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAsyncWriterBlockPolicyWaits(t *testing.T) {
+	dst := newGatedWriter()
+	aw := pslog.NewAsyncWriter(dst, pslog.AsyncOptions{Capacity: 1})
+	defer aw.Close()
+	logger := asyncTestLogger(aw)
+
+	logger.Info("a")
+	<-dst.started
+	logger.Info("b")
+	done := make(chan struct{})
+	go func() {
+		logger.Info("c")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("expected write to block while the buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(dst.release)
+	<-done
+	if err := aw.Flush(t.Context()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got := dst.String(); got != "INF a\nINF b\nINF c\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if stats := aw.Stats(); stats.Blocked != 1 || stats.Dropped != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAsyncWriterFlushHonoursContext(t *testing.T) {
+	dst := newGatedWriter()
+	aw := pslog.NewAsyncWriter(dst, pslog.AsyncOptions{})
+	asyncTestLogger(aw).Info("stuck")
+	<-dst.started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := aw.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	close(dst.release)
+	if err := aw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := dst.String(); got != "INF stuck\n" {
+		t.Fatalf("expected Close to drain, got %q", got)
+	}
+}
+
+func TestAsyncWriterClosedByContextCancellation(t *testing.T) {
+	var buf syncBuffer
+	aw := pslog.NewAsyncWriter(&buf, pslog.AsyncOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	logger := pslog.NewWithOptions(ctx, aw, pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, NoColor: true})
+	logger.Info("before")
+	cancel()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := aw.Write([]byte("probe\n")); errors.Is(err, os.ErrClosed) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("async writer was not closed by context cancellation")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !strings.HasPrefix(buf.String(), "INF before\n") {
+		t.Fatalf("expected buffered line to be drained, got %q", buf.String())
+	}
+}
+
+func TestAsyncWriterLoggerAllocatesZero(t *testing.T) {
+	aw := pslog.NewAsyncWriter(io.Discard, pslog.AsyncOptions{Policy: pslog.AsyncDropNewest})
+	defer aw.Close()
+	logger := pslog.NewWithOptions(nil, aw, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true})
+	keyvals := []any{"key", "value", "n", 42}
+	for range 2048 {
+		logger.Info("warm", keyvals...)
+	}
+	allocs := testing.AllocsPerRun(1000, func() {
+		logger.Info("msg", keyvals...)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected 0 allocs/log, got %.2f", allocs)
+	}
+}
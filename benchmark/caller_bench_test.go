@@ -0,0 +1,91 @@
+package benchmark_test
+
+import (
+	"testing"
+
+	pslog "pkt.systems/pslog"
+)
+
+type callerVariant struct {
+	name string
+	opts pslog.Options
+}
+
+func callerVariants() []callerVariant {
+	var variants []callerVariant
+	for _, mode := range []struct {
+		name string
+		mode pslog.CallerMode
+	}{
+		{"fn", pslog.CallerModeFunction},
+		{"pkg", pslog.CallerModePackage},
+		{"file", pslog.CallerModeShortFile},
+		{"path", pslog.CallerModeFullPath},
+	} {
+		for _, output := range []struct {
+			name string
+			opts pslog.Options
+		}{
+			{"json", pslog.Options{Mode: pslog.ModeStructured, NoColor: true}},
+			{"jsoncolor", pslog.Options{Mode: pslog.ModeStructured, ForceColor: true}},
+			{"console", pslog.Options{Mode: pslog.ModeConsole, NoColor: true}},
+			{"consolecolor", pslog.Options{Mode: pslog.ModeConsole, ForceColor: true}},
+		} {
+			opts := output.opts
+			opts.MinLevel = pslog.TraceLevel
+			opts.CallerKeyval = true
+			opts.CallerMode = mode.mode
+			variants = append(variants, callerVariant{name: output.name + "+caller=" + mode.name, opts: opts})
+		}
+	}
+	return variants
+}
+
+// BenchmarkPSLogProductionCaller runs the production dataset with CallerKeyval
+// enabled in every caller mode. Caller resolution is cached per program
+// counter, so these variants should report 0 allocs/op like their
+// caller-less counterparts.
+func BenchmarkPSLogProductionCaller(b *testing.B) {
+	entries := loadProductionEntries()
+	if len(entries) == 0 {
+		b.Fatal("no production entries loaded")
+	}
+	sink := newBenchmarkSink()
+	for _, variant := range callerVariants() {
+		b.Run("pslog/production/"+variant.name, func(b *testing.B) {
+			sink.resetCount()
+			logger := pslog.NewWithOptions(nil, sink, variant.opts)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				entries[i%len(entries)].log(logger)
+			}
+			if sink.bytesWritten() == 0 {
+				b.Fatalf("%s wrote zero bytes", variant.name)
+			}
+			reportBytesPerOp(b, sink)
+		})
+	}
+}
+
+func TestPSLogProductionCallerAllocFree(t *testing.T) {
+	entries := loadProductionEntries()
+	if len(entries) == 0 {
+		t.Fatal("no production entries loaded")
+	}
+	sink := newBenchmarkSink()
+	for _, variant := range callerVariants() {
+		logger := pslog.NewWithOptions(nil, sink, variant.opts)
+		for _, entry := range entries {
+			entry.log(logger)
+		}
+		i := 0
+		allocs := testing.AllocsPerRun(len(entries), func() {
+			entries[i%len(entries)].log(logger)
+			i++
+		})
+		if allocs != 0 {
+			t.Errorf("%s: expected 0 allocs/op, got %.2f", variant.name, allocs)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package pslog
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// callerStackDepth is the number of return addresses inspected on the hot
+	// path. Deeper lookups (large WithCallerSkip values) fall back to a heap
+	// buffer.
+	callerStackDepth = 32
+	// maxCallerCacheEntries bounds the per-PC cache; once full, new call sites
+	// are resolved without being stored.
+	maxCallerCacheEntries = 1 << 14
+	callerModeCount       = int(CallerModeFullPath) + 1
+)
+
+// callerFrame is one logical frame of a program counter. Inlined calls yield
+// several frames per PC. values holds the caller rendered in every
+// CallerMode, boxed once so the hot path appends it without allocating.
+type callerFrame struct {
+	internal bool
+	values   [callerModeCount]any
+}
+
+// callerCache maps program counters to their resolved frames. A sync.Map
+// serves hits without locking or writing shared memory; call sites are
+// finite, so it settles quickly. callerCacheLen bounds it.
+var (
+	callerCache    sync.Map // uintptr -> []callerFrame
+	callerCacheLen atomic.Int64
+)
+
+var unknownCallerValue any = unknownFunction
+
+// callerValue walks the stack and returns the first frame that is not within
+// the pslog module or log/slog (so records bridged through the slog handler
+// report the application caller), skipping skip further frames, rendered
+// according to mode.
+func callerValue(mode CallerMode, skip int) any {
+	var pcs [callerStackDepth]uintptr
+	// Skip runtime.Callers and callerValue.
+	n := runtime.Callers(2, pcs[:])
+	if value, ok := callerValueFromPCs(pcs[:n], mode, skip); ok {
+		return value
+	}
+	if n < len(pcs) {
+		return unknownCallerValue
+	}
+	deep := make([]uintptr, callerStackDepth*2+skip)
+	n = runtime.Callers(2, deep)
+	if value, ok := callerValueFromPCs(deep[:n], mode, skip); ok {
+		return value
+	}
+	return unknownCallerValue
+}
+
+// callerValueForPC renders the first frame of pc outside pslog, such as the
+// program counter of a log/slog record.
+func callerValueForPC(pc uintptr, mode CallerMode) any {
+	for _, frame := range cachedCallerFrames(pc) {
+		if !frame.internal {
+			return frame.values[mode]
+		}
+	}
+	return unknownCallerValue
+}
+
+func callerValueFromPCs(pcs []uintptr, mode CallerMode, skip int) (any, bool) {
+	for _, pc := range pcs {
+		for _, frame := range cachedCallerFrames(pc) {
+			if frame.internal {
+				continue
+			}
+			if skip == 0 {
+				return frame.values[mode], true
+			}
+			skip--
+		}
+	}
+	return nil, false
+}
+
+func cachedCallerFrames(pc uintptr) []callerFrame {
+	if frames, ok := callerCache.Load(pc); ok {
+		return frames.([]callerFrame)
+	}
+	return storeCallerFrames(pc, resolveCallerFrames(pc))
+}
+
+func resolveCallerFrames(pc uintptr) []callerFrame {
+	var out []callerFrame
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		frame, more := frames.Next()
+		resolved := callerFrame{internal: frame.Function == "" || isInternalFunction(frame.Function)}
+		if !resolved.internal {
+			for mode := range resolved.values {
+				resolved.values[mode] = formatCaller(frame, CallerMode(mode))
+			}
+		}
+		out = append(out, resolved)
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+// storeCallerFrames records frames for pc unless the cache is full. When
+// another goroutine stored pc first, its frames are returned instead.
+func storeCallerFrames(pc uintptr, frames []callerFrame) []callerFrame {
+	if callerCacheLen.Load() >= maxCallerCacheEntries {
+		return frames
+	}
+	cached, loaded := callerCache.LoadOrStore(pc, frames)
+	if !loaded {
+		callerCacheLen.Add(1)
+	}
+	return cached.([]callerFrame)
+}
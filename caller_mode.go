@@ -0,0 +1,81 @@
+package pslog
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CallerMode selects how the caller field enabled by Options.CallerKeyval is
+// rendered.
+type CallerMode uint8
+
+const (
+	// CallerModeFunction renders the bare function name, e.g. "handle". This
+	// is the default.
+	CallerModeFunction CallerMode = iota
+	// CallerModePackage renders the package-qualified function name, e.g.
+	// "server.(*API).handle".
+	CallerModePackage
+	// CallerModeShortFile renders the file name and line, e.g. "api.go:42".
+	CallerModeShortFile
+	// CallerModeFullPath renders the full file path and line, e.g.
+	// "/src/app/server/api.go:42".
+	CallerModeFullPath
+)
+
+// WithCallerSkip returns a logger whose caller field skips n additional
+// application frames. Wrapper libraries use it so the reported caller is
+// their caller rather than the wrapper itself; pslog's own frames are always
+// skipped. Loggers that are not backed by a pslog emitter are returned
+// unchanged.
+func WithCallerSkip(logger Logger, n int) Logger {
+	if n == 0 {
+		return logger
+	}
+	switch l := logger.(type) {
+	case *multiLogger:
+		return l.each(func(inner Logger) Logger { return WithCallerSkip(inner, n) })
+	case coreLogger:
+		return l.withCoreConfig(func(cfg *coreConfig) {
+			cfg.callerSkip = max(cfg.callerSkip+n, 0)
+		})
+	}
+	return logger
+}
+
+func normalizeCallerMode(mode CallerMode) CallerMode {
+	switch mode {
+	case CallerModePackage, CallerModeShortFile, CallerModeFullPath:
+		return mode
+	default:
+		return CallerModeFunction
+	}
+}
+
+// formatCaller renders frame according to mode.
+func formatCaller(frame runtime.Frame, mode CallerMode) string {
+	switch mode {
+	case CallerModePackage:
+		return trimPackagePath(frame.Function)
+	case CallerModeShortFile:
+		return filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+	case CallerModeFullPath:
+		return frame.File + ":" + strconv.Itoa(frame.Line)
+	default:
+		return trimFunctionName(frame.Function)
+	}
+}
+
+// trimPackagePath strips the import path directory from a fully qualified
+// function name, keeping the package name.
+func trimPackagePath(name string) string {
+	if name == "" {
+		return unknownFunction
+	}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
@@ -3,6 +3,10 @@ package pslog_test
 import (
 	"bytes"
 	"encoding/json"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 
 	pslog "pkt.systems/pslog"
@@ -81,3 +85,71 @@ func TestCallerKeyvalUsesCustomKey(t *testing.T) {
 		t.Fatalf("default fn field should be absent when custom caller key is set")
 	}
 }
+
+//go:noinline
+func callerWrapper(logger pslog.Logger) {
+	pslog.WithCallerSkip(logger, 1).Info("wrapped")
+}
+
+func TestCallerModes(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	cases := []struct {
+		mode  pslog.CallerMode
+		check func(string) bool
+	}{
+		{pslog.CallerModeFunction, func(v string) bool { return v == "callerAlpha" }},
+		{pslog.CallerModePackage, func(v string) bool { return v == "pslog_test.callerAlpha" }},
+		{pslog.CallerModeShortFile, func(v string) bool {
+			name, line, ok := strings.Cut(v, ":")
+			_, err := strconv.Atoi(line)
+			return ok && name == filepath.Base(file) && err == nil
+		}},
+		{pslog.CallerModeFullPath, func(v string) bool { return strings.HasPrefix(v, file+":") }},
+	}
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+			Mode:             pslog.ModeStructured,
+			NoColor:          true,
+			DisableTimestamp: true,
+			CallerKeyval:     true,
+			CallerMode:       tc.mode,
+		})
+		callerAlpha(logger)
+		payload := decodeJSONLine(t, strings.TrimSpace(buf.String()))
+		if got, _ := payload["fn"].(string); !tc.check(got) {
+			t.Fatalf("mode %d: unexpected caller %q", tc.mode, got)
+		}
+	}
+}
+
+func TestWithCallerSkipReportsWrapperCaller(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeConsole,
+		NoColor:          true,
+		DisableTimestamp: true,
+		CallerKeyval:     true,
+	})
+	callerWrapper(logger)
+	if got, want := strings.TrimSpace(buf.String()), "INF wrapped fn=TestWithCallerSkipReportsWrapperCaller"; got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestLoggerFromEnvCallerMode(t *testing.T) {
+	t.Setenv("PSLOG_TEST_CALLER_KEYVAL", "true")
+	t.Setenv("PSLOG_TEST_CALLER_MODE", "package")
+
+	var buf bytes.Buffer
+	logger := pslog.LoggerFromEnv(nil,
+		pslog.WithEnvPrefix("PSLOG_TEST_"),
+		pslog.WithEnvWriter(&buf),
+		pslog.WithEnvOptions(pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true}),
+	)
+	callerBeta(logger)
+	payload := decodeJSONLine(t, strings.TrimSpace(buf.String()))
+	if got := payload["fn"]; got != "pslog_test.callerBeta" {
+		t.Fatalf("unexpected caller %v", got)
+	}
+}
@@ -13,13 +13,17 @@ import (
 
 type consoleColorEmitFunc func(*consoleColorLogger, *lineWriter, Level, string, []any)
 
+type consoleColorWriteFunc func(l *consoleColorLogger, origin *recordOrigin, level Level, msg string, keyvals []any)
+
 type consoleColorLogger struct {
 	base         loggerBase
 	palette      *ansi.Palette
 	baseBytes    []byte
 	hasBaseBytes bool
+	groupPrefix  []byte
 	lineHint     *atomic.Int64
 	emit         consoleColorEmitFunc
+	write        consoleColorWriteFunc
 }
 
 func newConsoleColorLogger(ctx context.Context, cfg coreConfig, opts Options) *consoleColorLogger {
@@ -32,7 +36,8 @@ func newConsoleColorLogger(ctx context.Context, cfg coreConfig, opts Options) *c
 	}
 	owner := ownerToken(logger)
 	claimTimeCacheOwnership(cfg.timeCache, owner)
-	claimContextCancellation(ctx, cfg.writer, cfg.timeCache, owner)
+	cfg.sampler.claimOwnership(owner)
+	claimContextCancellation(ctx, cfg.writer, cfg.timeCache, cfg.sampler, owner)
 	logger.rebuildBaseBytes()
 	return logger
 }
@@ -75,7 +80,7 @@ func (l *consoleColorLogger) Error(msg string, keyvals ...any) { l.log(ErrorLeve
 
 func (l *consoleColorLogger) Fatal(msg string, keyvals ...any) {
 	l.log(FatalLevel, msg, keyvals...)
-	exitProcess()
+	l.base.cfg.fatalExit(ownerToken(l))
 }
 
 func (l *consoleColorLogger) Panic(msg string, keyvals ...any) {
@@ -91,8 +96,28 @@ func (l *consoleColorLogger) log(level Level, msg string, keyvals ...any) {
 	if !l.base.cfg.shouldLog(level) {
 		return
 	}
-	keyvals = l.base.maybeAddCaller(keyvals)
+	if l.base.cfg.sampler != nil && !l.base.cfg.sampler.allow(level, msg) {
+		return
+	}
+	l.write(l, nil, level, msg, keyvals)
+}
+
+func (l *consoleColorLogger) logRecord(origin *recordOrigin, level Level, msg string, keyvals []any) {
+	if !l.base.cfg.shouldLog(level) {
+		return
+	}
+	if l.base.cfg.sampler != nil && !l.base.cfg.sampler.allow(level, msg) {
+		return
+	}
+	l.write(l, origin, level, msg, keyvals)
+}
+
+// logDirect writes an entry for loggers without hooks or dynamic fields. It
+// runs after the level and sampling checks.
+func (l *consoleColorLogger) logDirect(origin *recordOrigin, level Level, msg string, keyvals []any) {
 	lw := acquireLineWriter(l.base.cfg.writer)
+	lw.origin = origin
+	keyvals = l.base.maybeAddCaller(lw, keyvals)
 	lw.autoFlush = false
 	if l.lineHint != nil {
 		if hint := l.lineHint.Load(); hint > 0 {
@@ -101,17 +126,54 @@ func (l *consoleColorLogger) log(level Level, msg string, keyvals ...any) {
 	}
 	l.emit(l, lw, level, msg, keyvals)
 	lw.finishLine()
-	lw.commit()
+	if async := l.base.cfg.async; async != nil {
+		async.commitLine(level, lw)
+	} else {
+		lw.commit()
+	}
 	l.recordHint(lw.lastLineLength())
 	releaseLineWriter(lw)
 }
 
+// logEntry is the cold path selected instead of logDirect when Options.Hooks
+// is set or the logger carries Lazy or Valuer fields bound via With.
+func (l *consoleColorLogger) logEntry(origin *recordOrigin, level Level, msg string, keyvals []any) {
+	entry := l.base.entry(level, msg, keyvals)
+	if entry == nil {
+		return
+	}
+	level, msg = entry.Level, entry.Message
+	lw := acquireLineWriter(l.base.cfg.writer)
+	lw.origin = origin
+	lw.entry = entry
+	keyvals = l.base.maybeAddCaller(lw, entry.Keyvals)
+	lw.autoFlush = false
+	if l.lineHint != nil {
+		if hint := l.lineHint.Load(); hint > 0 {
+			lw.preallocate(int(hint))
+		}
+	}
+	l.emit(l, lw, level, msg, keyvals)
+	if entry.stack != nil {
+		writeConsoleStack(lw, entry.stack)
+	}
+	lw.finishLine()
+	if async := l.base.cfg.async; async != nil {
+		async.commitLine(level, lw)
+	} else {
+		lw.commit()
+	}
+	l.recordHint(lw.lastLineLength())
+	releaseLineWriter(lw)
+	releaseEntry(entry)
+}
+
 func (l *consoleColorLogger) recordHint(n int) {
 	updateLineHint(l.lineHint, n)
 }
 
 func (l *consoleColorLogger) With(keyvals ...any) Logger {
-	fields := collectFields(keyvals)
+	fields := collectFields(keyvals, l.base.cfg.errors != nil)
 	if len(fields) == 0 {
 		return l
 	}
@@ -167,17 +229,72 @@ func (l *consoleColorLogger) LogLevelFromEnv(key string) Logger {
 	return l
 }
 
+func (l *consoleColorLogger) Named(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	clone.base.withName(name)
+	clone.rebuildBaseBytes()
+	return &clone
+}
+
+func (l *consoleColorLogger) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	clone.base.withGroup(name)
+	clone.rebuildBaseBytes()
+	return &clone
+}
+
+func (l *consoleColorLogger) core() *loggerBase { return &l.base }
+
+func (l *consoleColorLogger) withCoreConfig(adjust func(*coreConfig)) Logger {
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	adjust(&clone.base.cfg)
+	clone.rebuildBaseBytes()
+	return &clone
+}
+
 func (l *consoleColorLogger) Close() error {
-	return closeLoggerRuntime(l.base.cfg.writer, l.base.cfg.timeCache, ownerToken(l))
+	return closeLoggerRuntime(l.base.cfg.writer, l.base.cfg.timeCache, l.base.cfg.sampler, ownerToken(l))
 }
 
 func (l *consoleColorLogger) rebuildBaseBytes() {
-	l.baseBytes = encodeConsoleFieldsColor(l.base.fields, l.palette)
+	l.baseBytes = encodeConsoleFieldsColor(l.base.flatFields(), l.palette)
+	l.groupPrefix = l.base.groupPrefix()
 	l.hasBaseBytes = len(l.baseBytes) > 0
 	if l.base.cfg.includeLogLevel {
 		l.base.cfg.logLevelValue = LevelString(l.base.cfg.currentLevel())
 	}
 	l.emit = selectConsoleColorEmit(l.base.cfg, l.hasBaseBytes)
+	if len(l.groupPrefix) > 0 {
+		l.emit = emitConsoleColorGrouped
+	}
+	l.write = (*consoleColorLogger).logDirect
+	if l.base.hooked() {
+		l.write = (*consoleColorLogger).logEntry
+	}
 }
 
 func writeRuntimeConsoleColor(lw *lineWriter, keyvals []any, palette *ansi.Palette) {
@@ -208,15 +325,24 @@ func writeRuntimeConsoleColorFast(lw *lineWriter, keyvals []any, palette *ansi.P
 			pair++
 			continue
 		}
-		writeConsoleKeyColor(lw, key, palette)
-		value := keyvals[i+1]
-		writeConsoleValueColorInline(lw, value, palette)
+		value := resolveValue(keyvals[i+1])
+		if !writeConsoleMarshalerPair(lw, key, value, palette) {
+			writeConsoleKeyColor(lw, key, palette)
+			if !writeConsoleValueColorInline(lw, value, palette) {
+				writeConsoleValueColor(lw, value, palette)
+			}
+		}
 		pair++
 	}
 	if len(keyvals)%2 != 0 {
-		writeConsoleKeyColor(lw, argKeyName(pair), palette)
-		value := keyvals[len(keyvals)-1]
-		writeConsoleValueColorInline(lw, value, palette)
+		key := argKeyName(pair)
+		value := resolveValue(keyvals[len(keyvals)-1])
+		if !writeConsoleMarshalerPair(lw, key, value, palette) {
+			writeConsoleKeyColor(lw, key, palette)
+			if !writeConsoleValueColorInline(lw, value, palette) {
+				writeConsoleValueColor(lw, value, palette)
+			}
+		}
 	}
 	return true
 }
@@ -232,15 +358,24 @@ func writeRuntimeConsoleColorSlow(lw *lineWriter, keyvals []any, palette *ansi.P
 			pair++
 			continue
 		}
-		writeConsoleKeyColor(lw, key, palette)
-		value := keyvals[i+1]
-		writeConsoleValueColorInline(lw, value, palette)
+		value := resolveValue(keyvals[i+1])
+		if !writeConsoleMarshalerPair(lw, key, value, palette) {
+			writeConsoleKeyColor(lw, key, palette)
+			if !writeConsoleValueColorInline(lw, value, palette) {
+				writeConsoleValueColor(lw, value, palette)
+			}
+		}
 		pair++
 	}
 	if len(keyvals)%2 != 0 {
-		writeConsoleKeyColor(lw, argKeyName(pair), palette)
-		value := keyvals[len(keyvals)-1]
-		writeConsoleValueColorInline(lw, value, palette)
+		key := argKeyName(pair)
+		value := resolveValue(keyvals[len(keyvals)-1])
+		if !writeConsoleMarshalerPair(lw, key, value, palette) {
+			writeConsoleKeyColor(lw, key, palette)
+			if !writeConsoleValueColorInline(lw, value, palette) {
+				writeConsoleValueColor(lw, value, palette)
+			}
+		}
 	}
 }
 
@@ -253,6 +388,10 @@ func encodeConsoleFieldsColor(fields []field, palette *ansi.Palette) []byte {
 		if f.key == "" {
 			continue
 		}
+		if out, ok := appendConsoleMarshaler(buf, f.key, f.value, palette); ok {
+			buf = out
+			continue
+		}
 		buf = appendConsoleKeyColor(buf, f.key, palette)
 		buf = appendConsoleValueColor(buf, f.value, palette)
 	}
@@ -296,13 +435,41 @@ func selectConsoleColorEmit(cfg coreConfig, hasBaseFields bool) consoleColorEmit
 	}
 }
 
+// emitConsoleColorGrouped serves loggers with open groups. Runtime keys carry
+// the dotted group path; the caller and loglevel fields do not.
+func emitConsoleColorGrouped(l *consoleColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
+	levelColor, levelLabel := consoleLevelColor(level, l.palette)
+	estimate := len(l.baseBytes) + len(keyvals)*(20+len(l.groupPrefix)) + 4
+	estimate += len(levelLabel) + len(levelColor) + len(ansi.Reset)
+	if msg != "" {
+		estimate += len(l.palette.Message) + len(msg) + len(ansi.Reset) + 1
+	}
+	lw.reserve(estimate)
+	if l.base.cfg.includeTimestamp {
+		writeConsoleTimestampColor(lw, l.base.cfg.timestampFor(lw), l.palette)
+		lw.writeByte(' ')
+	}
+	writeConsoleColoredLiteral(lw, levelColor, levelLabel)
+	if msg != "" {
+		lw.writeByte(' ')
+		writeConsoleMessageColor(lw, msg, l.palette)
+	}
+	lw.writeBytes(l.baseBytes)
+	writeOuterConsoleGrouped(lw, l.base.groups, l.groupPrefix, l.palette)
+	writeRuntimeConsoleGrouped(lw, keyvals, l.groupPrefix, l.palette)
+	writeRuntimeConsoleColor(lw, lw.caller, l.palette)
+	if l.base.cfg.includeLogLevel {
+		writeConsoleFieldColor(lw, "loglevel", l.base.cfg.logLevelLabel(), l.palette)
+	}
+}
+
 func emitConsoleColorTimestampLogLevelWithBaseFields(l *consoleColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelColor, levelLabel := consoleLevelColor(level, l.palette)
 	estimate := len(l.baseBytes) + len(keyvals)*20 + 4
 	estimate += len(levelLabel) + len(levelColor) + len(ansi.Reset)
 	estimate += len(l.palette.Timestamp) + len(timestamp) + len(ansi.Reset) + 1
-	estimate += len(l.palette.Key) + len("loglevel=") + len(ansi.Reset) + len(l.palette.String) + len(l.base.cfg.logLevelValue) + len(ansi.Reset)
+	estimate += len(l.palette.Key) + len("loglevel=") + len(ansi.Reset) + len(l.palette.String) + len(l.base.cfg.logLevelLabel()) + len(ansi.Reset)
 	if msg != "" {
 		estimate += len(l.palette.Message) + len(msg) + len(ansi.Reset) + 1
 	}
@@ -316,16 +483,16 @@ func emitConsoleColorTimestampLogLevelWithBaseFields(l *consoleColorLogger, lw *
 	}
 	lw.writeBytes(l.baseBytes)
 	writeRuntimeConsoleColor(lw, keyvals, l.palette)
-	writeConsoleFieldColor(lw, "loglevel", l.base.cfg.logLevelValue, l.palette)
+	writeConsoleFieldColor(lw, "loglevel", l.base.cfg.logLevelLabel(), l.palette)
 }
 
 func emitConsoleColorTimestampLogLevelNoBaseFields(l *consoleColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelColor, levelLabel := consoleLevelColor(level, l.palette)
 	estimate := len(keyvals)*20 + 4
 	estimate += len(levelLabel) + len(levelColor) + len(ansi.Reset)
 	estimate += len(l.palette.Timestamp) + len(timestamp) + len(ansi.Reset) + 1
-	estimate += len(l.palette.Key) + len("loglevel=") + len(ansi.Reset) + len(l.palette.String) + len(l.base.cfg.logLevelValue) + len(ansi.Reset)
+	estimate += len(l.palette.Key) + len("loglevel=") + len(ansi.Reset) + len(l.palette.String) + len(l.base.cfg.logLevelLabel()) + len(ansi.Reset)
 	if msg != "" {
 		estimate += len(l.palette.Message) + len(msg) + len(ansi.Reset) + 1
 	}
@@ -338,11 +505,11 @@ func emitConsoleColorTimestampLogLevelNoBaseFields(l *consoleColorLogger, lw *li
 		writeConsoleMessageColor(lw, msg, l.palette)
 	}
 	writeRuntimeConsoleColor(lw, keyvals, l.palette)
-	writeConsoleFieldColor(lw, "loglevel", l.base.cfg.logLevelValue, l.palette)
+	writeConsoleFieldColor(lw, "loglevel", l.base.cfg.logLevelLabel(), l.palette)
 }
 
 func emitConsoleColorTimestampWithBaseFields(l *consoleColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelColor, levelLabel := consoleLevelColor(level, l.palette)
 	estimate := len(l.baseBytes) + len(keyvals)*20 + 4
 	estimate += len(levelLabel) + len(levelColor) + len(ansi.Reset)
@@ -363,7 +530,7 @@ func emitConsoleColorTimestampWithBaseFields(l *consoleColorLogger, lw *lineWrit
 }
 
 func emitConsoleColorTimestampNoBaseFields(l *consoleColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelColor, levelLabel := consoleLevelColor(level, l.palette)
 	estimate := len(keyvals)*20 + 4
 	estimate += len(levelLabel) + len(levelColor) + len(ansi.Reset)
@@ -386,7 +553,7 @@ func emitConsoleColorLogLevelWithBaseFields(l *consoleColorLogger, lw *lineWrite
 	levelColor, levelLabel := consoleLevelColor(level, l.palette)
 	estimate := len(l.baseBytes) + len(keyvals)*20 + 4
 	estimate += len(levelLabel) + len(levelColor) + len(ansi.Reset)
-	estimate += len(l.palette.Key) + len("loglevel=") + len(ansi.Reset) + len(l.palette.String) + len(l.base.cfg.logLevelValue) + len(ansi.Reset)
+	estimate += len(l.palette.Key) + len("loglevel=") + len(ansi.Reset) + len(l.palette.String) + len(l.base.cfg.logLevelLabel()) + len(ansi.Reset)
 	if msg != "" {
 		estimate += len(l.palette.Message) + len(msg) + len(ansi.Reset) + 1
 	}
@@ -398,14 +565,14 @@ func emitConsoleColorLogLevelWithBaseFields(l *consoleColorLogger, lw *lineWrite
 	}
 	lw.writeBytes(l.baseBytes)
 	writeRuntimeConsoleColor(lw, keyvals, l.palette)
-	writeConsoleFieldColor(lw, "loglevel", l.base.cfg.logLevelValue, l.palette)
+	writeConsoleFieldColor(lw, "loglevel", l.base.cfg.logLevelLabel(), l.palette)
 }
 
 func emitConsoleColorLogLevelNoBaseFields(l *consoleColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
 	levelColor, levelLabel := consoleLevelColor(level, l.palette)
 	estimate := len(keyvals)*20 + 4
 	estimate += len(levelLabel) + len(levelColor) + len(ansi.Reset)
-	estimate += len(l.palette.Key) + len("loglevel=") + len(ansi.Reset) + len(l.palette.String) + len(l.base.cfg.logLevelValue) + len(ansi.Reset)
+	estimate += len(l.palette.Key) + len("loglevel=") + len(ansi.Reset) + len(l.palette.String) + len(l.base.cfg.logLevelLabel()) + len(ansi.Reset)
 	if msg != "" {
 		estimate += len(l.palette.Message) + len(msg) + len(ansi.Reset) + 1
 	}
@@ -416,7 +583,7 @@ func emitConsoleColorLogLevelNoBaseFields(l *consoleColorLogger, lw *lineWriter,
 		writeConsoleMessageColor(lw, msg, l.palette)
 	}
 	writeRuntimeConsoleColor(lw, keyvals, l.palette)
-	writeConsoleFieldColor(lw, "loglevel", l.base.cfg.logLevelValue, l.palette)
+	writeConsoleFieldColor(lw, "loglevel", l.base.cfg.logLevelLabel(), l.palette)
 }
 
 func emitConsoleColorBaseWithBaseFields(l *consoleColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
@@ -544,6 +711,8 @@ func writeConsoleValueColorFast(lw *lineWriter, value any, palette *ansi.Palette
 	case time.Duration:
 		writeConsoleStringColor(lw, lw.formatDuration(v), palette.String)
 		return true
+	case ObjectMarshaler, ArrayMarshaler:
+		return false
 	case stringer:
 		writeConsoleStringColor(lw, v.String(), palette.String)
 		return true
@@ -658,6 +827,8 @@ func writeConsoleValueColorInline(lw *lineWriter, value any, palette *ansi.Palet
 	case time.Duration:
 		writeConsoleStringColor(lw, lw.formatDuration(v), palette.String)
 		return true
+	case ObjectMarshaler, ArrayMarshaler:
+		return false
 	case stringer:
 		writeConsoleStringColor(lw, v.String(), palette.String)
 		return true
@@ -670,6 +841,8 @@ func writeConsoleValueColorInline(lw *lineWriter, value any, palette *ansi.Palet
 	case nil:
 		writeConsoleStringColor(lw, "nil", palette.Nil)
 		return true
+	case deferred:
+		return false
 	default:
 		writePTLogValueColored(lw, v, palette.String)
 		return true
@@ -687,6 +860,8 @@ func writeConsoleValueColor(lw *lineWriter, value any, palette *ansi.Palette) {
 		writeConsoleStringColor(lw, lw.formatTimeRFC3339(v), palette.Timestamp)
 	case time.Duration:
 		writeConsoleStringColor(lw, lw.formatDuration(v), palette.String)
+	case ObjectMarshaler, ArrayMarshaler:
+		writePTLogValueColored(lw, v, palette.String)
 	case stringer:
 		writeConsoleStringColor(lw, v.String(), palette.String)
 	case error:
@@ -723,6 +898,8 @@ func writeConsoleValueColor(lw *lineWriter, value any, palette *ansi.Palette) {
 		writeConsoleStringColor(lw, string(v), palette.String)
 	case nil:
 		writeConsoleStringColor(lw, "nil", palette.Nil)
+	case deferred:
+		writeConsoleValueColor(lw, resolveValue(v), palette)
 	default:
 		writePTLogValueColored(lw, v, palette.String)
 	}
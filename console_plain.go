@@ -9,12 +9,16 @@ import (
 
 type consolePlainEmitFunc func(*consolePlainLogger, *lineWriter, Level, string, []any)
 
+type consolePlainWriteFunc func(l *consolePlainLogger, origin *recordOrigin, level Level, msg string, keyvals []any)
+
 type consolePlainLogger struct {
 	base         loggerBase
 	baseBytes    []byte
 	hasBaseBytes bool
+	groupPrefix  []byte
 	lineHint     *atomic.Int64
 	emit         consolePlainEmitFunc
+	write        consolePlainWriteFunc
 }
 
 func newConsolePlainLogger(ctx context.Context, cfg coreConfig, opts Options) *consolePlainLogger {
@@ -25,7 +29,8 @@ func newConsolePlainLogger(ctx context.Context, cfg coreConfig, opts Options) *c
 	}
 	owner := ownerToken(logger)
 	claimTimeCacheOwnership(cfg.timeCache, owner)
-	claimContextCancellation(ctx, cfg.writer, cfg.timeCache, owner)
+	cfg.sampler.claimOwnership(owner)
+	claimContextCancellation(ctx, cfg.writer, cfg.timeCache, cfg.sampler, owner)
 	logger.rebuildBaseBytes()
 	return logger
 }
@@ -38,7 +43,7 @@ func (l *consolePlainLogger) Error(msg string, keyvals ...any) { l.log(ErrorLeve
 
 func (l *consolePlainLogger) Fatal(msg string, keyvals ...any) {
 	l.log(FatalLevel, msg, keyvals...)
-	exitProcess()
+	l.base.cfg.fatalExit(ownerToken(l))
 }
 
 func (l *consolePlainLogger) Panic(msg string, keyvals ...any) {
@@ -54,8 +59,28 @@ func (l *consolePlainLogger) log(level Level, msg string, keyvals ...any) {
 	if !l.base.cfg.shouldLog(level) {
 		return
 	}
-	keyvals = l.base.maybeAddCaller(keyvals)
+	if l.base.cfg.sampler != nil && !l.base.cfg.sampler.allow(level, msg) {
+		return
+	}
+	l.write(l, nil, level, msg, keyvals)
+}
+
+func (l *consolePlainLogger) logRecord(origin *recordOrigin, level Level, msg string, keyvals []any) {
+	if !l.base.cfg.shouldLog(level) {
+		return
+	}
+	if l.base.cfg.sampler != nil && !l.base.cfg.sampler.allow(level, msg) {
+		return
+	}
+	l.write(l, origin, level, msg, keyvals)
+}
+
+// logDirect writes an entry for loggers without hooks or dynamic fields. It
+// runs after the level and sampling checks.
+func (l *consolePlainLogger) logDirect(origin *recordOrigin, level Level, msg string, keyvals []any) {
 	lw := acquireLineWriter(l.base.cfg.writer)
+	lw.origin = origin
+	keyvals = l.base.maybeAddCaller(lw, keyvals)
 	lw.autoFlush = false
 	if l.lineHint != nil {
 		if hint := l.lineHint.Load(); hint > 0 {
@@ -64,17 +89,54 @@ func (l *consolePlainLogger) log(level Level, msg string, keyvals ...any) {
 	}
 	l.emit(l, lw, level, msg, keyvals)
 	lw.finishLine()
-	lw.commit()
+	if async := l.base.cfg.async; async != nil {
+		async.commitLine(level, lw)
+	} else {
+		lw.commit()
+	}
 	l.recordHint(lw.lastLineLength())
 	releaseLineWriter(lw)
 }
 
+// logEntry is the cold path selected instead of logDirect when Options.Hooks
+// is set or the logger carries Lazy or Valuer fields bound via With.
+func (l *consolePlainLogger) logEntry(origin *recordOrigin, level Level, msg string, keyvals []any) {
+	entry := l.base.entry(level, msg, keyvals)
+	if entry == nil {
+		return
+	}
+	level, msg = entry.Level, entry.Message
+	lw := acquireLineWriter(l.base.cfg.writer)
+	lw.origin = origin
+	lw.entry = entry
+	keyvals = l.base.maybeAddCaller(lw, entry.Keyvals)
+	lw.autoFlush = false
+	if l.lineHint != nil {
+		if hint := l.lineHint.Load(); hint > 0 {
+			lw.preallocate(int(hint))
+		}
+	}
+	l.emit(l, lw, level, msg, keyvals)
+	if entry.stack != nil {
+		writeConsoleStack(lw, entry.stack)
+	}
+	lw.finishLine()
+	if async := l.base.cfg.async; async != nil {
+		async.commitLine(level, lw)
+	} else {
+		lw.commit()
+	}
+	l.recordHint(lw.lastLineLength())
+	releaseLineWriter(lw)
+	releaseEntry(entry)
+}
+
 func (l *consolePlainLogger) recordHint(n int) {
 	updateLineHint(l.lineHint, n)
 }
 
 func (l *consolePlainLogger) With(keyvals ...any) Logger {
-	fields := collectFields(keyvals)
+	fields := collectFields(keyvals, l.base.cfg.errors != nil)
 	if len(fields) == 0 {
 		return l
 	}
@@ -130,17 +192,72 @@ func (l *consolePlainLogger) LogLevelFromEnv(key string) Logger {
 	return l
 }
 
+func (l *consolePlainLogger) Named(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	clone.base.withName(name)
+	clone.rebuildBaseBytes()
+	return &clone
+}
+
+func (l *consolePlainLogger) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	clone.base.withGroup(name)
+	clone.rebuildBaseBytes()
+	return &clone
+}
+
+func (l *consolePlainLogger) core() *loggerBase { return &l.base }
+
+func (l *consolePlainLogger) withCoreConfig(adjust func(*coreConfig)) Logger {
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	adjust(&clone.base.cfg)
+	clone.rebuildBaseBytes()
+	return &clone
+}
+
 func (l *consolePlainLogger) Close() error {
-	return closeLoggerRuntime(l.base.cfg.writer, l.base.cfg.timeCache, ownerToken(l))
+	return closeLoggerRuntime(l.base.cfg.writer, l.base.cfg.timeCache, l.base.cfg.sampler, ownerToken(l))
 }
 
 func (l *consolePlainLogger) rebuildBaseBytes() {
-	l.baseBytes = encodeConsoleFieldsPlain(l.base.fields)
+	l.baseBytes = encodeConsoleFieldsPlain(l.base.flatFields())
+	l.groupPrefix = l.base.groupPrefix()
 	l.hasBaseBytes = len(l.baseBytes) > 0
 	if l.base.cfg.includeLogLevel {
 		l.base.cfg.logLevelValue = LevelString(l.base.cfg.currentLevel())
 	}
 	l.emit = selectConsolePlainEmit(l.base.cfg, l.hasBaseBytes)
+	if len(l.groupPrefix) > 0 {
+		l.emit = emitConsolePlainGrouped
+	}
+	l.write = (*consolePlainLogger).logDirect
+	if l.base.hooked() {
+		l.write = (*consolePlainLogger).logEntry
+	}
 }
 
 func encodeConsoleFieldsPlain(fields []field) []byte {
@@ -152,6 +269,10 @@ func encodeConsoleFieldsPlain(fields []field) []byte {
 		if f.key == "" {
 			continue
 		}
+		if out, ok := appendConsoleMarshaler(buf, f.key, f.value, nil); ok {
+			buf = out
+			continue
+		}
 		buf = append(buf, ' ')
 		buf = append(buf, f.key...)
 		buf = append(buf, '=')
@@ -188,22 +309,27 @@ func writeRuntimeConsolePlainFast(lw *lineWriter, keyvals []any) bool {
 			pair++
 			continue
 		}
-		lw.writeByte(' ')
-		lw.writeString(key)
-		lw.writeByte('=')
-		value := keyvals[i+1]
-		if !writeConsoleValueInline(lw, value) {
-			writeConsoleValuePlain(lw, value)
+		value := resolveValue(keyvals[i+1])
+		if !writeConsoleMarshalerPair(lw, key, value, nil) {
+			lw.writeByte(' ')
+			lw.writeString(key)
+			lw.writeByte('=')
+			if !writeConsoleValueInline(lw, value) {
+				writeConsoleValuePlain(lw, value)
+			}
 		}
 		pair++
 	}
 	if len(keyvals)%2 != 0 {
-		lw.writeByte(' ')
-		lw.writeString(argKeyName(pair))
-		lw.writeByte('=')
-		value := keyvals[len(keyvals)-1]
-		if !writeConsoleValueInline(lw, value) {
-			writeConsoleValuePlain(lw, value)
+		key := argKeyName(pair)
+		value := resolveValue(keyvals[len(keyvals)-1])
+		if !writeConsoleMarshalerPair(lw, key, value, nil) {
+			lw.writeByte(' ')
+			lw.writeString(key)
+			lw.writeByte('=')
+			if !writeConsoleValueInline(lw, value) {
+				writeConsoleValuePlain(lw, value)
+			}
 		}
 	}
 	return true
@@ -220,22 +346,27 @@ func writeRuntimeConsolePlainSlow(lw *lineWriter, keyvals []any) {
 			pair++
 			continue
 		}
-		lw.writeByte(' ')
-		lw.writeString(key)
-		lw.writeByte('=')
-		value := keyvals[i+1]
-		if !writeConsoleValueFast(lw, value) {
-			writeConsoleValuePlain(lw, value)
+		value := resolveValue(keyvals[i+1])
+		if !writeConsoleMarshalerPair(lw, key, value, nil) {
+			lw.writeByte(' ')
+			lw.writeString(key)
+			lw.writeByte('=')
+			if !writeConsoleValueFast(lw, value) {
+				writeConsoleValuePlain(lw, value)
+			}
 		}
 		pair++
 	}
 	if len(keyvals)%2 != 0 {
-		lw.writeByte(' ')
-		lw.writeString(argKeyName(pair))
-		lw.writeByte('=')
-		value := keyvals[len(keyvals)-1]
-		if !writeConsoleValueFast(lw, value) {
-			writeConsoleValuePlain(lw, value)
+		key := argKeyName(pair)
+		value := resolveValue(keyvals[len(keyvals)-1])
+		if !writeConsoleMarshalerPair(lw, key, value, nil) {
+			lw.writeByte(' ')
+			lw.writeString(key)
+			lw.writeByte('=')
+			if !writeConsoleValueFast(lw, value) {
+				writeConsoleValuePlain(lw, value)
+			}
 		}
 	}
 }
@@ -279,12 +410,39 @@ func selectConsolePlainEmit(cfg coreConfig, hasBaseFields bool) consolePlainEmit
 	}
 }
 
+// emitConsolePlainGrouped serves loggers with open groups. Runtime keys carry
+// the dotted group path; the caller and loglevel fields do not.
+func emitConsolePlainGrouped(l *consolePlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
+	levelLabel := consoleLevelPlain(level)
+	estimate := len(levelLabel) + len(l.baseBytes) + len(keyvals)*(16+len(l.groupPrefix)) + 4
+	if msg != "" {
+		estimate += len(msg) + 1
+	}
+	lw.reserve(estimate)
+	if l.base.cfg.includeTimestamp {
+		writeConsoleTimestampPlain(lw, l.base.cfg.timestampFor(lw))
+		lw.writeByte(' ')
+	}
+	lw.writeString(levelLabel)
+	if msg != "" {
+		lw.writeByte(' ')
+		writeConsoleMessagePlain(lw, msg)
+	}
+	lw.writeBytes(l.baseBytes)
+	writeOuterConsoleGrouped(lw, l.base.groups, l.groupPrefix, nil)
+	writeRuntimeConsoleGrouped(lw, keyvals, l.groupPrefix, nil)
+	writeRuntimeConsolePlain(lw, lw.caller)
+	if l.base.cfg.includeLogLevel {
+		writeConsoleFieldPlain(lw, "loglevel", l.base.cfg.logLevelLabel())
+	}
+}
+
 func emitConsolePlainTimestampLogLevelWithBaseFields(l *consolePlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelLabel := consoleLevelPlain(level)
 	estimate := len(levelLabel) + len(l.baseBytes) + len(keyvals)*16 + 4
 	estimate += len(timestamp) + 1
-	estimate += len(" loglevel=") + len(l.base.cfg.logLevelValue)
+	estimate += len(" loglevel=") + len(l.base.cfg.logLevelLabel())
 	if msg != "" {
 		estimate += len(msg) + 1
 	}
@@ -298,15 +456,15 @@ func emitConsolePlainTimestampLogLevelWithBaseFields(l *consolePlainLogger, lw *
 	}
 	lw.writeBytes(l.baseBytes)
 	writeRuntimeConsolePlain(lw, keyvals)
-	writeConsoleFieldPlain(lw, "loglevel", l.base.cfg.logLevelValue)
+	writeConsoleFieldPlain(lw, "loglevel", l.base.cfg.logLevelLabel())
 }
 
 func emitConsolePlainTimestampLogLevelNoBaseFields(l *consolePlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelLabel := consoleLevelPlain(level)
 	estimate := len(levelLabel) + len(keyvals)*16 + 4
 	estimate += len(timestamp) + 1
-	estimate += len(" loglevel=") + len(l.base.cfg.logLevelValue)
+	estimate += len(" loglevel=") + len(l.base.cfg.logLevelLabel())
 	if msg != "" {
 		estimate += len(msg) + 1
 	}
@@ -319,11 +477,11 @@ func emitConsolePlainTimestampLogLevelNoBaseFields(l *consolePlainLogger, lw *li
 		writeConsoleMessagePlain(lw, msg)
 	}
 	writeRuntimeConsolePlain(lw, keyvals)
-	writeConsoleFieldPlain(lw, "loglevel", l.base.cfg.logLevelValue)
+	writeConsoleFieldPlain(lw, "loglevel", l.base.cfg.logLevelLabel())
 }
 
 func emitConsolePlainTimestampWithBaseFields(l *consolePlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelLabel := consoleLevelPlain(level)
 	estimate := len(levelLabel) + len(l.baseBytes) + len(keyvals)*16 + 4
 	estimate += len(timestamp) + 1
@@ -343,7 +501,7 @@ func emitConsolePlainTimestampWithBaseFields(l *consolePlainLogger, lw *lineWrit
 }
 
 func emitConsolePlainTimestampNoBaseFields(l *consolePlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelLabel := consoleLevelPlain(level)
 	estimate := len(levelLabel) + len(keyvals)*16 + 4
 	estimate += len(timestamp) + 1
@@ -364,7 +522,7 @@ func emitConsolePlainTimestampNoBaseFields(l *consolePlainLogger, lw *lineWriter
 func emitConsolePlainLogLevelWithBaseFields(l *consolePlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
 	levelLabel := consoleLevelPlain(level)
 	estimate := len(levelLabel) + len(l.baseBytes) + len(keyvals)*16 + 4
-	estimate += len(" loglevel=") + len(l.base.cfg.logLevelValue)
+	estimate += len(" loglevel=") + len(l.base.cfg.logLevelLabel())
 	if msg != "" {
 		estimate += len(msg) + 1
 	}
@@ -376,13 +534,13 @@ func emitConsolePlainLogLevelWithBaseFields(l *consolePlainLogger, lw *lineWrite
 	}
 	lw.writeBytes(l.baseBytes)
 	writeRuntimeConsolePlain(lw, keyvals)
-	writeConsoleFieldPlain(lw, "loglevel", l.base.cfg.logLevelValue)
+	writeConsoleFieldPlain(lw, "loglevel", l.base.cfg.logLevelLabel())
 }
 
 func emitConsolePlainLogLevelNoBaseFields(l *consolePlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
 	levelLabel := consoleLevelPlain(level)
 	estimate := len(levelLabel) + len(keyvals)*16 + 4
-	estimate += len(" loglevel=") + len(l.base.cfg.logLevelValue)
+	estimate += len(" loglevel=") + len(l.base.cfg.logLevelLabel())
 	if msg != "" {
 		estimate += len(msg) + 1
 	}
@@ -393,7 +551,7 @@ func emitConsolePlainLogLevelNoBaseFields(l *consolePlainLogger, lw *lineWriter,
 		writeConsoleMessagePlain(lw, msg)
 	}
 	writeRuntimeConsolePlain(lw, keyvals)
-	writeConsoleFieldPlain(lw, "loglevel", l.base.cfg.logLevelValue)
+	writeConsoleFieldPlain(lw, "loglevel", l.base.cfg.logLevelLabel())
 }
 
 func emitConsolePlainBaseWithBaseFields(l *consolePlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
@@ -521,6 +679,8 @@ func writeConsoleValueFast(lw *lineWriter, value any) bool {
 	case time.Duration:
 		writeConsoleStringPlain(lw, lw.formatDuration(v))
 		return true
+	case ObjectMarshaler, ArrayMarshaler:
+		return false
 	case stringer:
 		writeConsoleStringPlain(lw, v.String())
 		return true
@@ -635,6 +795,8 @@ func writeConsoleValueInline(lw *lineWriter, value any) bool {
 	case time.Duration:
 		writeConsoleStringPlain(lw, lw.formatDuration(v))
 		return true
+	case ObjectMarshaler, ArrayMarshaler:
+		return false
 	case stringer:
 		writeConsoleStringPlain(lw, v.String())
 		return true
@@ -660,6 +822,8 @@ func writeConsoleValuePlain(lw *lineWriter, value any) {
 		writeConsoleStringPlain(lw, lw.formatTimeRFC3339(v))
 	case time.Duration:
 		writeConsoleStringPlain(lw, lw.formatDuration(v))
+	case ObjectMarshaler, ArrayMarshaler:
+		writePTLogValue(lw, v)
 	case stringer:
 		writeConsoleStringPlain(lw, v.String())
 	case error:
@@ -696,6 +860,10 @@ func writeConsoleValuePlain(lw *lineWriter, value any) {
 		writeConsoleStringPlain(lw, string(v))
 	case nil:
 		writeConsoleStringPlain(lw, "nil")
+	case deferred:
+		if resolved := v.resolve(); !writeConsoleValueInline(lw, resolved) {
+			writeConsoleValuePlain(lw, resolved)
+		}
 	default:
 		writePTLogValue(lw, v)
 	}
@@ -8,6 +8,7 @@ import (
 const (
 	unknownFunction = "unknown"
 	pslogModulePath = "pkt.systems/pslog"
+	slogPackagePath = "log/slog"
 )
 
 // CurrentFn returns the name of the calling function without package path. If
@@ -58,31 +59,8 @@ func trimFunctionName(name string) string {
 	return name
 }
 
-// callerFunctionName walks the stack and returns the first frame that is not
-// within the pslog module. It mirrors CurrentFn's formatting.
-func callerFunctionName() string {
-	pcs := make([]uintptr, 16)
-	// Skip runtime.Callers and callerFunctionName.
-	n := runtime.Callers(2, pcs)
-	if n == 0 {
-		return unknownFunction
-	}
-	frames := runtime.CallersFrames(pcs[:n])
-	for {
-		frame, more := frames.Next()
-		if frame.Function == "" {
-			if !more {
-				break
-			}
-			continue
-		}
-		if strings.HasPrefix(frame.Function, pslogModulePath+".") || strings.HasPrefix(frame.Function, pslogModulePath+"/") {
-			if !more {
-				break
-			}
-			continue
-		}
-		return trimFunctionName(frame.Function)
-	}
-	return unknownFunction
+// isInternalFunction reports whether fn belongs to the pslog module or
+// log/slog; such frames are skipped when looking for the application caller.
+func isInternalFunction(fn string) bool {
+	return strings.HasPrefix(fn, pslogModulePath+".") || strings.HasPrefix(fn, pslogModulePath+"/") || strings.HasPrefix(fn, slogPackagePath+".")
 }
@@ -1,6 +1,11 @@
 package pslog
 
-import "testing"
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
 
 // The helper functions are marked noinline to keep their stack frames visible to
 // runtime.Caller during the test.
@@ -55,3 +60,35 @@ func TestCurrentFnStripsReceiverAndPackage(t *testing.T) {
 		t.Fatalf("CurrentFn pointer receiver mismatch: got %q, want %q", got, want)
 	}
 }
+
+func TestCachedCallerFramesStoresPC(t *testing.T) {
+	var pcs [1]uintptr
+	if runtime.Callers(1, pcs[:]) == 0 {
+		t.Fatal("no program counter")
+	}
+	pc := pcs[0]
+	frames := cachedCallerFrames(pc)
+	if len(frames) == 0 || !frames[0].internal {
+		t.Fatalf("expected the pslog test frame to be marked internal, got %+v", frames)
+	}
+	cached, ok := callerCache.Load(pc)
+	if !ok || &cached.([]callerFrame)[0] != &frames[0] {
+		t.Fatalf("expected the resolved frames to be cached for pc %#x", pc)
+	}
+	if again := cachedCallerFrames(pc); &again[0] != &frames[0] {
+		t.Fatal("expected a cache hit to return the stored frames")
+	}
+}
+
+func TestCallerValueSkipsWrapperFrames(t *testing.T) {
+	var pcs [callerStackDepth]uintptr
+	n := runtime.Callers(1, pcs[:])
+	value, ok := callerValueFromPCs(pcs[:n], CallerModeFullPath, 0)
+	if !ok {
+		t.Fatal("expected a frame outside pslog")
+	}
+	_, self, _, _ := runtime.Caller(0)
+	if caller := value.(string); strings.HasPrefix(caller, filepath.Dir(self)+string(filepath.Separator)) {
+		t.Fatalf("expected the first frame outside pslog, got %v", caller)
+	}
+}
@@ -81,8 +81,68 @@
 //     NaN/+Inf/-Inf (string or null).
 //   - The ansi subpackage also exposes palette lookup helpers
 //     (ansi.PaletteByName and ansi.AvailablePaletteNames).
+//   - Options.LevelVar shares an atomically adjustable level across a logger
+//     and everything derived from it via With.
+//   - Named builds dotted logger names (`logger=db.pool`) whose levels
+//     can be overridden via Options.NamedLevels or LOG_LEVEL=info,db=debug.
+//   - Options.Sampling keeps the first N entries per level+message and
+//     interval, then every Mth, and reports how many were suppressed.
+//   - Options.Hooks runs a chain of hooks that can inspect, mutate or drop
+//     entries; loggers without hooks keep the regular hot path.
+//   - pslog.Lazy defers computing a value until the entry is written, and
+//     pslog.Valuer bound via With is re-evaluated for every entry.
+//   - WithGroup nests subsequent With fields and runtime keyvals under a
+//     JSON object, or a dotted key prefix in console mode.
+//   - ObjectMarshaler and ArrayMarshaler types encode themselves through
+//     ObjectEncoder/ArrayEncoder: nested JSON in structured mode, dotted
+//     key.sub=value pairs in console mode.
+//   - Options.Redact masks values by key name or glob and by value pattern;
+//     pslog.Secret values always render as [REDACTED].
+//   - Options.ErrorMode set to ErrorModeRich adds the Unwrap chain, joined
+//     errors and StackTrace() frames next to each error value.
+//   - Options.CallerMode renders the caller as function, package.function,
+//     file:line or full path:line; pslog.WithCallerSkip lets wrappers skip
+//     their own frames.
+//   - Options.StackTraceLevel attaches the goroutine's stack, without pslog
+//     frames, to entries at or above the level.
+//   - pslog.Recover and pslog.Go log panics at PanicLevel with the value and
+//     stack; pslog.SetCrashOutput sends runtime crash reports to the log file.
+//   - Fatal runs Options.Fatal.ShutdownHooks, closes logger-owned outputs
+//     (draining an AsyncWriter) and exits with Options.Fatal.ExitCode;
+//     Options.Fatal.Exit replaces os.Exit in tests.
+//   - pslog.Close drains and closes what a logger owns (AsyncWriter,
+//     LOG_OUTPUT files, the timestamp cache, the sampling summary ticker)
+//     and reports the close error;
+//     pslog.Flush waits for buffered output without closing.
+//   - Package pslogtest records entries in memory for assertions
+//     (RequireEntry, NoErrors, query predicates), intercepts Fatal and Panic,
+//     and provides a logger that writes through testing.TB.Log.
+//   - pslog.NewMulti fans entries out to several sinks, each with its own
+//     writer, Mode, MinLevel, palette and colour decision; With fields are
+//     shared and encoded once per sink.
+//   - pslog.NewRotatingFile rotates by size or hourly/daily, keeps segments by
+//     count and age, gzips them in the background and links path to the
+//     active segment; LOG_OUTPUT=rotate:/path?max=100MB&keep=7 enables it.
+//   - pslog.NewReopenFile reopens its path on SIGHUP or when the file is
+//     replaced, for logrotate-style external rotation (LOG_OUTPUT=reopen:/path).
+//   - pslog.NewSyslogWriter sends structured lines as RFC 5424 messages over
+//     UDP, TCP (octet-counted) or unix sockets, with levels mapped to
+//     severities and fields in STRUCTURED-DATA or the message as JSON
+//     (LOG_OUTPUT=syslog+tcp://host:514).
+//   - pslog.NewJournalWriter sends entries to systemd-journald over the
+//     native protocol with PRIORITY, CODE_FUNC and sanitized upper-case
+//     fields, passing oversized entries as a file (LOG_OUTPUT=journald).
+//   - pslog.NewAsyncWriter buffers lines and writes them from a background
+//     goroutine with block, drop-newest or drop-oldest policies; errors are
+//     never dropped. Flush waits for the buffer and Close drains it.
+//   - pslog.NewLevelHandler serves GET/PUT of a LevelVar over HTTP with an
+//     optional auto-revert TTL.
 //   - pslog.LogLogger bridges to the standard library by returning a *log.Logger
 //     that feeds through to pslog.
+//   - pslog.NewSlogHandler returns a log/slog Handler rendered by the pslog
+//     emitters, so *slog.Logger consumers share the same output.
+//   - pslog.LoggerFromSlogHandler wraps an existing slog.Handler as a Logger,
+//     converting entries into slog.Records.
 //
 // Benchmarks, tooling, and the elevator pitch visualiser are under the
 // benchmark/ and elevatorpitch/ directories respectively.
@@ -0,0 +1,336 @@
+package pslog
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// ErrorMode controls how error values are rendered.
+type ErrorMode uint8
+
+const (
+	// ErrorModeMessage renders errors as their Error() string. This is the
+	// default.
+	ErrorModeMessage ErrorMode = iota
+	// ErrorModeRich renders an error under its key plus a `<key>_chain` field
+	// describing every Unwrap level, with joined errors expanded. Errors that
+	// expose a StackTrace() method add a `<key>_stack` field. Console loggers
+	// use a compact form: the chain as `type > type` and only the innermost
+	// stack frame, as `<key>_at`.
+	ErrorModeRich
+)
+
+// maxErrorChainDepth bounds the Unwrap walk so cyclic chains terminate.
+const maxErrorChainDepth = 32
+
+// capturedError is the value collected for a bare With(err) under
+// ErrorModeRich. The message is taken once while the original stays available
+// for expansion; other modes keep only an errorMessage.
+type capturedError struct {
+	msg string
+	err error
+}
+
+func (e capturedError) Error() string { return e.msg }
+
+func (e capturedError) Unwrap() error { return e.err }
+
+// errorRenderer expands error values according to ErrorModeRich. It runs as
+// the last hook for runtime keyvals and over the static fields when the base
+// payload is rebuilt.
+type errorRenderer struct {
+	console bool
+}
+
+func newErrorRenderer(mode ErrorMode, console bool) *errorRenderer {
+	if mode != ErrorModeRich {
+		return nil
+	}
+	return &errorRenderer{console: console}
+}
+
+// expandableError returns the error to expand, or nil when v is not an error or
+// has already been reduced to its message (for example by redaction).
+func expandableError(v any) error {
+	switch err := v.(type) {
+	case errorMessage:
+		return nil
+	case capturedError:
+		if err.err == nil {
+			return nil
+		}
+		return err.err
+	case error:
+		return err
+	}
+	return nil
+}
+
+// appendPairs appends the rendered pairs for err under key.
+func (r *errorRenderer) appendPairs(dst []any, key string, err error) []any {
+	chain := errorChainOf(err, 0)
+	dst = append(dst, key, errorMessage(err.Error()))
+	if r.console {
+		dst = append(dst, key+"_chain", compactErrorChain(chain))
+		if frames := errorStackOf(err); len(frames) > 0 {
+			dst = append(dst, key+"_at", frames[0])
+		}
+		return dst
+	}
+	dst = append(dst, key+"_chain", chain)
+	if frames := errorStackOf(err); len(frames) > 0 {
+		dst = append(dst, key+"_stack", stackFrames(frames))
+	}
+	return dst
+}
+
+// hook expands error values in the runtime keyvals. Trailing values without a
+// key are left alone.
+func (r *errorRenderer) hook(e *Entry) bool {
+	idx := -1
+	for i := 0; i+1 < len(e.Keyvals); i += 2 {
+		if expandableError(e.Keyvals[i+1]) != nil {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return true
+	}
+	out := make([]any, 0, len(e.Keyvals)+4)
+	out = append(out, e.Keyvals[:idx]...)
+	pair := idx / 2
+	for i := idx; i < len(e.Keyvals); i += 2 {
+		if i+1 >= len(e.Keyvals) {
+			out = append(out, e.Keyvals[i])
+			break
+		}
+		key := keyFromValue(e.Keyvals[i], pair)
+		if err := expandableError(e.Keyvals[i+1]); err != nil && key != "" {
+			out = r.appendPairs(out, key, err)
+		} else {
+			out = append(out, e.Keyvals[i], e.Keyvals[i+1])
+		}
+		pair++
+	}
+	e.Keyvals = out
+	e.scratch = out
+	e.owned = true
+	return true
+}
+
+// fields expands error values among the static fields, sharing the input when
+// there are none.
+func (r *errorRenderer) fields(fields []field) []field {
+	idx := -1
+	for i := range fields {
+		if expandableError(fields[i].value) != nil {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fields
+	}
+	out := make([]field, 0, len(fields)+2)
+	out = append(out, fields[:idx]...)
+	var pairs []any
+	for _, f := range fields[idx:] {
+		err := expandableError(f.value)
+		if err == nil || f.key == "" {
+			out = append(out, f)
+			continue
+		}
+		pairs = r.appendPairs(pairs[:0], f.key, err)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			key := pairs[i].(string)
+			out = append(out, field{key: key, value: pairs[i+1], trustedKey: stringTrustedASCII(key), group: f.group})
+		}
+	}
+	return out
+}
+
+// errorNode is one level of an error chain. Joined holds the chains of the
+// members of an errors.Join style error.
+type errorNode struct {
+	typ    string
+	msg    string
+	joined []errorChain
+}
+
+// errorChain renders as a JSON array of {"type","msg"} objects.
+type errorChain []errorNode
+
+func errorChainOf(err error, depth int) errorChain {
+	var chain errorChain
+	for err != nil && depth < maxErrorChainDepth {
+		if captured, ok := err.(capturedError); ok {
+			err = captured.err
+			continue
+		}
+		node := errorNode{typ: fmt.Sprintf("%T", err), msg: err.Error()}
+		depth++
+		switch u := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, member := range u.Unwrap() {
+				if member != nil {
+					node.joined = append(node.joined, errorChainOf(member, depth))
+				}
+			}
+			err = nil
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		default:
+			err = nil
+		}
+		chain = append(chain, node)
+	}
+	return chain
+}
+
+// MarshalLogArray implements ArrayMarshaler.
+func (c errorChain) MarshalLogArray(enc *ArrayEncoder) {
+	for i := range c {
+		enc.AppendObject(&c[i])
+	}
+}
+
+// MarshalLogObject implements ObjectMarshaler.
+func (n *errorNode) MarshalLogObject(enc *ObjectEncoder) {
+	enc.String("type", n.typ)
+	enc.String("msg", n.msg)
+	if len(n.joined) > 0 {
+		enc.Array("joined", ArrayMarshalerFunc(func(enc *ArrayEncoder) {
+			for _, chain := range n.joined {
+				enc.AppendArray(chain)
+			}
+		}))
+	}
+}
+
+// compactErrorChain renders the chain types for console output, e.g.
+// "*fmt.wrapError > *fs.PathError > syscall.Errno". Joined members are listed
+// in brackets, separated by "|".
+func compactErrorChain(chain errorChain) string {
+	var b strings.Builder
+	writeCompactErrorChain(&b, chain)
+	return b.String()
+}
+
+func writeCompactErrorChain(b *strings.Builder, chain errorChain) {
+	for i, node := range chain {
+		if i > 0 {
+			b.WriteString(" > ")
+		}
+		b.WriteString(node.typ)
+		if len(node.joined) == 0 {
+			continue
+		}
+		b.WriteByte('[')
+		for j, member := range node.joined {
+			if j > 0 {
+				b.WriteString(" | ")
+			}
+			writeCompactErrorChain(b, member)
+		}
+		b.WriteByte(']')
+	}
+}
+
+// errorStackOf returns the stack of the innermost error in the Unwrap chain
+// that exposes one, innermost frame first.
+func errorStackOf(err error) []string {
+	var frames []string
+	for depth := 0; err != nil && depth < maxErrorChainDepth; depth++ {
+		if stack := stackTraceOf(err); len(stack) > 0 {
+			frames = stack
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return frames
+}
+
+// stackTraceOf calls a StackTrace() method on err. Slices of program counters
+// (including named types such as github.com/pkg/errors.StackTrace), slices of
+// strings or fmt.Stringers, and newline separated strings are understood.
+func stackTraceOf(err error) []string {
+	switch st := err.(type) {
+	case interface{ StackTrace() []uintptr }:
+		return framesFromPCs(st.StackTrace())
+	case interface{ StackTrace() []string }:
+		return st.StackTrace()
+	case interface{ StackTrace() string }:
+		return splitStackLines(st.StackTrace())
+	}
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+	out := method.Call(nil)[0]
+	switch out.Kind() {
+	case reflect.String:
+		return splitStackLines(out.String())
+	case reflect.Slice:
+	default:
+		return nil
+	}
+	n := out.Len()
+	if n == 0 {
+		return nil
+	}
+	switch out.Type().Elem().Kind() {
+	case reflect.Uintptr:
+		pcs := make([]uintptr, n)
+		for i := range pcs {
+			pcs[i] = uintptr(out.Index(i).Uint())
+		}
+		return framesFromPCs(pcs)
+	case reflect.String:
+		frames := make([]string, n)
+		for i := range frames {
+			frames[i] = out.Index(i).String()
+		}
+		return frames
+	}
+	frames := make([]string, 0, n)
+	for i := range n {
+		if s, ok := out.Index(i).Interface().(fmt.Stringer); ok {
+			frames = append(frames, s.String())
+		}
+	}
+	return frames
+}
+
+func framesFromPCs(pcs []uintptr) []string {
+	if len(pcs) == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames(pcs)
+	out := make([]string, 0, len(pcs))
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" || frame.File != "" {
+			out = append(out, formatFrame(frame))
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+func splitStackLines(s string) []string {
+	var out []string
+	for line := range strings.SplitSeq(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package pslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+type frame uintptr
+
+type stackTrace []frame
+
+type stackedError struct {
+	msg   string
+	stack stackTrace
+}
+
+func (e *stackedError) Error() string { return e.msg }
+
+func (e *stackedError) StackTrace() stackTrace { return e.stack }
+
+func newStackedError(msg string) error {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(1, pcs)
+	stack := make(stackTrace, n)
+	for i, pc := range pcs[:n] {
+		stack[i] = frame(pc)
+	}
+	return &stackedError{msg: msg, stack: stack}
+}
+
+func TestErrorModeRichJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true, ErrorMode: pslog.ErrorModeRich})
+	err := fmt.Errorf("load: %w", errors.Join(errors.New("a"), errors.New("b")))
+	logger.Info("failed", "err", err)
+
+	want := `{"lvl":"info","msg":"failed","err":"load: a\nb","err_chain":[` +
+		`{"type":"*fmt.wrapError","msg":"load: a\nb"},` +
+		`{"type":"*errors.joinError","msg":"a\nb","joined":[[{"type":"*errors.errorString","msg":"a"}],[{"type":"*errors.errorString","msg":"b"}]]}]}`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestErrorModeRichStackTrace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, ForceColor: true, ErrorMode: pslog.ErrorModeRich})
+	logger.With(fmt.Errorf("wrapped: %w", newStackedError("boom"))).Error("failed")
+
+	var entry struct {
+		Error string   `json:"error"`
+		Stack []string `json:"error_stack"`
+	}
+	if err := json.Unmarshal([]byte(stripANSI(buf.String())), &entry); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if entry.Error != "wrapped: boom" {
+		t.Fatalf("unexpected error field %q", entry.Error)
+	}
+	if len(entry.Stack) == 0 || !strings.HasPrefix(entry.Stack[0], "pkt.systems/pslog_test.newStackedError ") || !strings.Contains(entry.Stack[0], "error_mode_test.go:") {
+		t.Fatalf("unexpected stack %q", entry.Stack)
+	}
+}
+
+func TestErrorModeRichConsole(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, NoColor: true, ErrorMode: pslog.ErrorModeRich})
+	err := fmt.Errorf("load: %w", errors.Join(errors.New("a"), newStackedError("b")))
+	logger.Info("failed", "err", err)
+
+	got := strings.TrimSpace(buf.String())
+	want := `INF failed err="load: a\nb" err_chain="*fmt.wrapError > *errors.joinError[*errors.errorString | *pslog_test.stackedError]"`
+	if got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+
+	buf.Reset()
+	logger.Info("failed", "err", newStackedError("boom"))
+	if got := buf.String(); !strings.Contains(got, ` err_at="pkt.systems/pslog_test.newStackedError `) {
+		t.Fatalf("expected innermost frame, got %q", got)
+	}
+}
+
+func TestErrorModeMessageUnchanged(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true})
+	err := fmt.Errorf("load: %w", errors.New("a"))
+	logger.With(err).Info("failed", "err", err)
+	if got, want := strings.TrimSpace(buf.String()), `{"lvl":"info","msg":"failed","error":"load: a","err":"load: a"}`; got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestErrorModeRichRespectsRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		ErrorMode:        pslog.ErrorModeRich,
+		Redact:           &pslog.Redaction{Keys: []string{"secret_err"}},
+	})
+	logger.Info("failed", "secret_err", fmt.Errorf("token abc: %w", errors.New("x")))
+	if got, want := strings.TrimSpace(buf.String()), `{"lvl":"info","msg":"failed","secret_err":"[REDACTED]"}`; got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestErrorModeFromEnv(t *testing.T) {
+	t.Setenv("LOG_ERROR_MODE", "rich")
+	t.Setenv("LOG_MODE", "json")
+	t.Setenv("LOG_DISABLE_TIMESTAMP", "true")
+	t.Setenv("LOG_NO_COLOR", "true")
+	var buf bytes.Buffer
+	logger := pslog.LoggerFromEnv(nil, pslog.WithEnvWriter(&buf))
+	logger.Info("failed", "err", errors.New("a"))
+	if got, want := strings.TrimSpace(buf.String()), `{"lvl":"info","msg":"failed","err":"a","err_chain":[{"type":"*errors.errorString","msg":"a"}]}`; got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
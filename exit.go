@@ -2,6 +2,77 @@ package pslog
 
 import "os"
 
-var exitProcess = func() {
-	os.Exit(1)
+// exitProcess terminates the process when Options.Fatal.Exit is not set.
+var exitProcess = func(code int) {
+	os.Exit(code)
+}
+
+// FatalOptions configures what Fatal does after its entry has been written:
+// run the shutdown hooks, close the logger-owned outputs (files opened via
+// LOG_OUTPUT, AsyncWriters, which drain first), the timestamp cache and the
+// sampling summary ticker, then exit with ExitCode.
+type FatalOptions struct {
+	// ExitCode is the process exit status. Zero means 1.
+	ExitCode int
+
+	// ShutdownHooks run in order before the outputs are closed, e.g. to flush
+	// metrics or tracing exporters. A panicking hook is skipped so the
+	// process still exits.
+	ShutdownHooks []func()
+
+	// Exit replaces os.Exit, typically in tests. When it returns, Fatal
+	// returns to its caller; the logger's owned outputs are closed by then.
+	Exit func(code int)
+}
+
+type fatalConfig struct {
+	code  int
+	hooks []func()
+	exit  func(int)
+}
+
+func newFatalConfig(opts FatalOptions) *fatalConfig {
+	if opts.ExitCode == 0 && len(opts.ShutdownHooks) == 0 && opts.Exit == nil {
+		return nil
+	}
+	cfg := &fatalConfig{code: opts.ExitCode, exit: opts.Exit}
+	if cfg.code == 0 {
+		cfg.code = 1
+	}
+	for _, hook := range opts.ShutdownHooks {
+		if hook != nil {
+			cfg.hooks = append(cfg.hooks, hook)
+		}
+	}
+	return cfg
+}
+
+// fatalExit finishes a Fatal call for the logger identified by owner.
+func (c coreConfig) fatalExit(owner uintptr) {
+	c.fatal.run(func() {
+		_ = closeLoggerRuntime(c.writer, c.timeCache, c.sampler, owner)
+	})
+}
+
+// run executes the shutdown hooks, then closeOutputs, then exits. f may be
+// nil, which selects the defaults.
+func (f *fatalConfig) run(closeOutputs func()) {
+	code := 1
+	exit := exitProcess
+	if f != nil {
+		for _, hook := range f.hooks {
+			runShutdownHook(hook)
+		}
+		code = f.code
+		if f.exit != nil {
+			exit = f.exit
+		}
+	}
+	closeOutputs()
+	exit(code)
+}
+
+func runShutdownHook(hook func()) {
+	defer func() { _ = recover() }()
+	hook()
 }
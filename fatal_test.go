@@ -0,0 +1,88 @@
+package pslog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFatalRunsHooksClosesOutputAndExits(t *testing.T) {
+	for _, mode := range []Mode{ModeConsole, ModeStructured} {
+		tracker := &closeTrackingWriter{}
+		var buf bytes.Buffer
+		var order []string
+		exitCode := -1
+		output := newOwnedOutput(&buf, tracker)
+		logger := NewWithOptions(nil, output, Options{
+			Mode:             mode,
+			NoColor:          true,
+			DisableTimestamp: true,
+			Fatal: FatalOptions{
+				ExitCode: 3,
+				ShutdownHooks: []func(){
+					func() { order = append(order, "first") },
+					func() { panic("ignored") },
+					func() {
+						if tracker.closed.Load() {
+							t.Errorf("output closed before shutdown hooks ran")
+						}
+						order = append(order, "second")
+					},
+				},
+				Exit: func(code int) {
+					order = append(order, "exit")
+					exitCode = code
+				},
+			},
+		})
+
+		logger.With("job", 1).Fatal("bye")
+
+		if got := strings.Join(order, ","); got != "first,second,exit" {
+			t.Fatalf("mode %v: unexpected order %q", mode, got)
+		}
+		if exitCode != 3 {
+			t.Fatalf("mode %v: expected exit code 3, got %d", mode, exitCode)
+		}
+		if !tracker.closed.Load() {
+			t.Fatalf("mode %v: expected owned output to be closed", mode)
+		}
+		if !strings.Contains(buf.String(), "bye") {
+			t.Fatalf("mode %v: expected fatal entry, got %q", mode, buf.String())
+		}
+	}
+}
+
+func TestFatalDrainsAsyncWriterBeforeExit(t *testing.T) {
+	var buf bytes.Buffer
+	async := NewAsyncWriter(&buf, AsyncOptions{})
+	var drained string
+	logger := NewWithOptions(nil, async, Options{
+		Mode:             ModeStructured,
+		NoColor:          true,
+		DisableTimestamp: true,
+		Fatal: FatalOptions{
+			Exit: func(int) { drained = buf.String() },
+		},
+	})
+
+	logger.Info("queued")
+	logger.Fatal("bye")
+
+	if !strings.Contains(drained, `"msg":"queued"`) || !strings.Contains(drained, `"msg":"bye"`) {
+		t.Fatalf("expected async entries to be written before exit, got %q", drained)
+	}
+}
+
+func TestFatalDefaultsToExitCodeOne(t *testing.T) {
+	prev := exitProcess
+	defer func() { exitProcess = prev }()
+	code := -1
+	exitProcess = func(c int) { code = c }
+
+	NewStructured(nil, &bytes.Buffer{}).Fatal("bye")
+
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+}
@@ -0,0 +1,82 @@
+package pslog
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Closer is implemented by loggers that own resources. Every logger returned
+// by the New* constructors and LoggerFromEnv implements it; see Close.
+type Closer interface {
+	Close() error
+}
+
+// Flusher is implemented by loggers and writers that buffer output. Flush
+// returns once everything written before the call has reached the underlying
+// destination, or with ctx's error when ctx ends first.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Close releases what logger owns and reports the close error: an AsyncWriter
+// is drained and closed, files opened via LOG_OUTPUT are closed, and the
+// timestamp cache and sampling summary ticker started for the logger are
+// stopped, the ticker after writing a last summary. Writers passed in by the
+// caller are left open, with one exception: an AsyncWriter is closed even
+// when the caller created it, so every logger writing to it loses it; see
+// NewAsyncWriter. Loggers derived with With, Named and so on share the
+// output, so closing any of them closes it for all; the timestamp cache and
+// the summary ticker are only stopped by the logger that created them. Close is safe to call more than
+// once and returns nil for loggers that own nothing.
+func Close(logger Base) error {
+	if c, ok := logger.(Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Flush waits until every entry logged through logger before the call has
+// been handed to the destination. AsyncWriters are flushed, as are writers
+// with a Flush(context.Context) error or Flush() error method (such as
+// *bufio.Writer) found behind pslog's own wrappers. Loggers implementing
+// Flusher handle the call themselves.
+func Flush(ctx context.Context, logger Base) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if f, ok := logger.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	if cl, ok := logger.(coreLogger); ok {
+		return flushWriter(ctx, cl.core().cfg.writer)
+	}
+	return nil
+}
+
+func flushWriter(ctx context.Context, w io.Writer) error {
+	switch v := w.(type) {
+	case *AsyncWriter:
+		if err := v.Flush(ctx); err != nil {
+			return err
+		}
+		return flushWriter(ctx, v.dst)
+	case *ownedOutput:
+		return flushWriter(ctx, v.writer)
+	case *ObservedWriter:
+		return flushWriter(ctx, v.dst)
+	case *teeWriter:
+		var errs []error
+		for _, inner := range v.writers {
+			if err := flushWriter(ctx, inner); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	case Flusher:
+		return v.Flush(ctx)
+	case interface{ Flush() error }:
+		return v.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package pslog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	_ Closer = (*jsonPlainLogger)(nil)
+	_ Closer = (*jsonColorLogger)(nil)
+	_ Closer = (*consolePlainLogger)(nil)
+	_ Closer = (*consoleColorLogger)(nil)
+)
+
+type errorCloser struct{ err error }
+
+func (c errorCloser) Close() error { return c.err }
+
+func TestCloseClosesEnvOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("LOG_OUTPUT", path)
+	t.Setenv("LOG_MODE", "json")
+	logger := LoggerFromEnv(context.Background())
+	logger.Info("before")
+
+	if err := Close(logger); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := Close(logger); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+	cl := logger.(coreLogger)
+	file := cl.core().cfg.writer.(*ownedOutput).closer.(*os.File)
+	if _, err := file.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("expected output file to be closed, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(data), `"msg":"before"`) {
+		t.Fatalf("unexpected file content %q", data)
+	}
+}
+
+func TestCloseReportsOutputError(t *testing.T) {
+	want := errors.New("close failed")
+	logger := NewStructured(nil, newOwnedOutput(&bytes.Buffer{}, errorCloser{err: want}))
+	if err := Close(logger); !errors.Is(err, want) {
+		t.Fatalf("expected %v, got %v", want, err)
+	}
+}
+
+func TestCloseStopsOwnedTimeCache(t *testing.T) {
+	logger := NewStructured(nil, &bytes.Buffer{})
+	cache := logger.(coreLogger).core().cfg.timeCache
+	if cache == nil {
+		t.Fatalf("expected a time cache")
+	}
+	if err := Close(logger.With("k", "v")); err != nil {
+		t.Fatalf("close derived: %v", err)
+	}
+	if cache.isStopped() {
+		t.Fatalf("derived logger must not stop the root's time cache")
+	}
+	if err := Close(logger); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if !cache.isStopped() {
+		t.Fatalf("expected time cache to be stopped")
+	}
+}
+
+func TestCloseLeavesCallerWriterOpen(t *testing.T) {
+	writer := &closeTrackingWriter{}
+	if err := Close(NewStructured(nil, writer)); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if writer.closed.Load() {
+		t.Fatalf("caller-provided writer must stay open")
+	}
+	if err := Close(noopLogger{}); err != nil {
+		t.Fatalf("noop close: %v", err)
+	}
+}
+
+func TestFlushDrainsAsyncAndBufferedWriters(t *testing.T) {
+	var buf bytes.Buffer
+	buffered := bufio.NewWriterSize(&buf, 64<<10)
+	async := NewAsyncWriter(buffered, AsyncOptions{})
+	defer async.Close()
+	logger := NewWithOptions(nil, async, Options{Mode: ModeStructured, NoColor: true, DisableTimestamp: true})
+
+	logger.Info("one")
+	logger.With("k", "v").Info("two")
+	if err := Flush(context.Background(), logger); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, `"msg":"one"`) || !strings.Contains(got, `"msg":"two"`) {
+		t.Fatalf("expected flushed entries, got %q", got)
+	}
+}
+
+func TestFlushHonoursContext(t *testing.T) {
+	gate := make(chan struct{})
+	async := NewAsyncWriter(blockingWriter{gate: gate}, AsyncOptions{})
+	defer func() {
+		close(gate)
+		_ = async.Close()
+	}()
+	logger := NewStructured(nil, async)
+	logger.Info("stuck")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Flush(ctx, logger); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+type blockingWriter struct{ gate chan struct{} }
+
+func (w blockingWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	return len(p), nil
+}
@@ -0,0 +1,193 @@
+package pslog
+
+import (
+	"slices"
+	"strings"
+
+	"pkt.systems/pslog/ansi"
+)
+
+// GroupLogger is implemented by loggers that support field groups. Every
+// logger pslog constructs implements it. WithGroup is kept off Logger so
+// existing Logger implementations, mocks and wrappers stay valid; use the
+// package-level WithGroup to group any Logger.
+type GroupLogger interface {
+	Logger
+	// WithGroup returns a logger that nests fields added afterwards, and the
+	// runtime keyvals of every log call, under name. Structured output renders
+	// groups as nested objects; console output joins them into dotted keys
+	// such as `http.method`. An empty name returns the receiver unchanged.
+	WithGroup(name string) Logger
+}
+
+// WithGroup returns logger with the group name opened when it implements
+// GroupLogger and logger unchanged otherwise.
+func WithGroup(logger Logger, name string) Logger {
+	if grouped, ok := logger.(GroupLogger); ok {
+		return grouped.WithGroup(name)
+	}
+	return logger
+}
+
+// withGroup opens a group; fields added afterwards and runtime keyvals are
+// nested under it.
+func (b *loggerBase) withGroup(name string) {
+	b.groups = append(slices.Clip(b.groups), name)
+}
+
+// flatFields returns the static fields with group names folded into dotted
+// keys, as rendered by the console emitters.
+func (b *loggerBase) flatFields() []field {
+	fields := b.payloadFields()
+	if len(b.groups) == 0 {
+		return fields
+	}
+	out := cloneFields(fields)
+	for i := range out {
+		if g := out[i].group; g > 0 && out[i].key != "" {
+			out[i].key = strings.Join(b.groups[:g], ".") + "." + out[i].key
+			out[i].trustedKey = stringTrustedASCII(out[i].key)
+		}
+	}
+	return out
+}
+
+// groupPrefix returns the dotted prefix console emitters put in front of
+// runtime keys.
+func (b *loggerBase) groupPrefix() []byte {
+	if len(b.groups) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(b.groups, ".") + ".")
+}
+
+// jsonGroupLayout is the pre-encoded form of a grouped JSON payload. runs
+// holds the static fields encoded per group depth, with a leading comma per
+// field, and opens holds every group key followed by its opening brace.
+// Groups are opened while the line is written, so groups that end up without
+// fields are left out.
+type jsonGroupLayout struct {
+	runs  []jsonGroupRun
+	opens [][]byte
+	size  int
+}
+
+type jsonGroupRun struct {
+	depth int
+	data  []byte
+}
+
+// encodeJSONGroups lays out fields for a grouped JSON logger. encode renders
+// a run of fields with a leading comma per field; open renders a group key
+// with a leading comma, followed by the opening brace.
+func encodeJSONGroups(fields []field, groups []string, encode func([]field) []byte, open func(string) []byte) jsonGroupLayout {
+	var layout jsonGroupLayout
+	for _, name := range groups {
+		key := open(name)
+		layout.opens = append(layout.opens, key)
+		layout.size += len(key) + 1
+	}
+	for start := 0; start < len(fields); {
+		end := start + 1
+		for end < len(fields) && fields[end].group == fields[start].group {
+			end++
+		}
+		run := encode(fields[start:end])
+		depth := min(fields[start].group, len(groups))
+		start = end
+		if len(run) == 0 {
+			continue
+		}
+		layout.runs = append(layout.runs, jsonGroupRun{depth: depth, data: run})
+		layout.size += len(run)
+	}
+	return layout
+}
+
+// write renders the static runs, the dynamic fields bound outside the
+// innermost group and keyvals, which go inside the innermost group. A dynamic
+// field follows the static fields of its own group. Every group opened along
+// the way is closed again before write returns. A nil palette renders plain
+// output.
+func (g *jsonGroupLayout) write(lw *lineWriter, first *bool, keyvals []any, palette *ansi.Palette) {
+	outer, depths := lw.entry.outerFields()
+	depth, j := 0, 0
+	for _, run := range g.runs {
+		for ; j < len(depths) && depths[j] < run.depth; j++ {
+			depth = g.openTo(lw, first, depth, depths[j])
+			writeRuntimeJSONFields(lw, first, outer[j*2:j*2+2], palette)
+		}
+		depth = g.openTo(lw, first, depth, run.depth)
+		appendKeyDataWithFirst(lw, first, run.data)
+	}
+	for ; j < len(depths); j++ {
+		depth = g.openTo(lw, first, depth, depths[j])
+		writeRuntimeJSONFields(lw, first, outer[j*2:j*2+2], palette)
+	}
+	if len(keyvals) > 0 {
+		depth = g.openTo(lw, first, depth, len(g.opens))
+		writeRuntimeJSONFields(lw, first, keyvals, palette)
+	}
+	closeJSONGroups(lw, first, depth)
+}
+
+// openTo opens the groups between depth and target and returns the new depth.
+func (g *jsonGroupLayout) openTo(lw *lineWriter, first *bool, depth, target int) int {
+	for ; depth < target; depth++ {
+		appendKeyDataWithFirst(lw, first, g.opens[depth])
+		*first = true
+	}
+	return depth
+}
+
+func writeRuntimeJSONFields(lw *lineWriter, first *bool, keyvals []any, palette *ansi.Palette) {
+	if palette == nil {
+		writeRuntimeJSONFieldsPlain(lw, first, keyvals)
+		return
+	}
+	writeRuntimeJSONFieldsColor(lw, first, keyvals, palette)
+}
+
+// closeJSONGroups closes n groups and returns to the top-level object.
+func closeJSONGroups(lw *lineWriter, first *bool, n int) {
+	for range n {
+		lw.writeByte('}')
+	}
+	*first = false
+}
+
+// writeOuterConsoleGrouped writes the dynamic fields bound outside the
+// innermost group, prefixing each key with the dotted path of the group it
+// was bound in. prefix is the logger's full group prefix.
+func writeOuterConsoleGrouped(lw *lineWriter, groups []string, prefix []byte, palette *ansi.Palette) {
+	outer, depths := lw.entry.outerFields()
+	for j, depth := range depths {
+		n := 0
+		for _, name := range groups[:depth] {
+			n += len(name) + 1
+		}
+		writeRuntimeConsoleGrouped(lw, outer[j*2:j*2+2], prefix[:n], palette)
+	}
+}
+
+// writeRuntimeConsoleGrouped writes runtime keyvals for console loggers with
+// open groups, prefixing every key with the dotted group path. A nil palette
+// renders plain output.
+func writeRuntimeConsoleGrouped(lw *lineWriter, keyvals []any, prefix []byte, palette *ansi.Palette) {
+	if len(keyvals) == 0 {
+		return
+	}
+	e := lw.objectEncoder(true, palette)
+	e.prefix = append(e.prefix, prefix...)
+	pair := 0
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if key := keyFromValue(keyvals[i], pair); key != "" {
+			e.any(key, -1, keyvals[i+1])
+		}
+		pair++
+	}
+	if len(keyvals)%2 != 0 {
+		e.any(argKeyName(pair), -1, keyvals[len(keyvals)-1])
+	}
+	e.release()
+}
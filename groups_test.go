@@ -0,0 +1,118 @@
+package pslog_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+func TestWithGroupAcrossVariants(t *testing.T) {
+	variants := []struct {
+		name string
+		opts pslog.Options
+		want []string
+	}{
+		{"json_plain", pslog.Options{Mode: pslog.ModeStructured, NoColor: true}, []string{
+			`{"lvl":"info","msg":"req","app":"api","logger":"web","http":{"method":"GET","req":{"id":7}},"fn":"func1","loglevel":"debug"}`,
+			`{"lvl":"info","msg":"idle","app":"api","logger":"web","http":{"method":"GET"},"fn":"func1","loglevel":"debug"}`,
+			`{"lvl":"info","msg":"empty","app":"api","logger":"web","http":{"method":"GET"},"fn":"func1","loglevel":"debug"}`,
+		}},
+		{"json_color", pslog.Options{Mode: pslog.ModeStructured, ForceColor: true}, []string{
+			`{"lvl":"info","msg":"req","app":"api","logger":"web","http":{"method":"GET","req":{"id":7}},"fn":"func1","loglevel":"debug"}`,
+			`{"lvl":"info","msg":"idle","app":"api","logger":"web","http":{"method":"GET"},"fn":"func1","loglevel":"debug"}`,
+			`{"lvl":"info","msg":"empty","app":"api","logger":"web","http":{"method":"GET"},"fn":"func1","loglevel":"debug"}`,
+		}},
+		{"console_plain", pslog.Options{Mode: pslog.ModeConsole, NoColor: true}, []string{
+			`INF req app=api logger=web http.method=GET http.req.id=7 fn=func1 loglevel=debug`,
+			`INF idle app=api logger=web http.method=GET fn=func1 loglevel=debug`,
+			`INF empty app=api logger=web http.method=GET fn=func1 loglevel=debug`,
+		}},
+		{"console_color", pslog.Options{Mode: pslog.ModeConsole, ForceColor: true}, []string{
+			`INF req app=api logger=web http.method=GET http.req.id=7 fn=func1 loglevel=debug`,
+			`INF idle app=api logger=web http.method=GET fn=func1 loglevel=debug`,
+			`INF empty app=api logger=web http.method=GET fn=func1 loglevel=debug`,
+		}},
+	}
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := variant.opts
+			opts.DisableTimestamp = true
+			opts.CallerKeyval = true
+			opts.MinLevel = pslog.DebugLevel
+			base := pslog.NewWithOptions(nil, &buf, opts).With("app", "api")
+			grouped := pslog.WithGroup(pslog.WithGroup(base, "http").With("method", "GET"), "req")
+			logger := pslog.Named(grouped, "web").WithLogLevel()
+			logger.Info("req", "id", 7)
+			logger.Info("idle")
+			pslog.WithGroup(pslog.WithGroup(logger, ""), "unused").Info("empty")
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != len(variant.want) {
+				t.Fatalf("expected %d lines, got %q", len(variant.want), buf.String())
+			}
+			for i, line := range lines {
+				if got := stripANSI(line); got != variant.want[i] {
+					t.Fatalf("line %d:\n got %s\nwant %s", i, got, variant.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWithGroupLeavesParentUntouched(t *testing.T) {
+	var buf bytes.Buffer
+	parent := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true})
+	grouped := pslog.WithGroup(parent, "db").With("table", "users")
+	grouped.Info("query", "rows", 3)
+	parent.Info("plain", "rows", 3)
+
+	want := `{"lvl":"info","msg":"query","db":{"table":"users","rows":3}}` + "\n" +
+		`{"lvl":"info","msg":"plain","rows":3}` + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestWithGroupWrapsMarshalersAndHookedEntries(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeConsole,
+		DisableTimestamp: true,
+		NoColor:          true,
+		Hooks: []pslog.Hook{func(e *pslog.Entry) bool {
+			e.Set("hooked", true)
+			return true
+		}},
+	})
+	pslog.WithGroup(logger, "ctx").Info("obj", "user", pslog.ObjectMarshalerFunc(func(enc *pslog.ObjectEncoder) {
+		enc.String("name", "ada")
+	}))
+	if got, want := strings.TrimSpace(buf.String()), `INF obj ctx.user.name=ada ctx.hooked=true`; got != want {
+		t.Fatalf("got %s\nwant %s", got, want)
+	}
+}
+
+func TestWithGroupKeepsCallerTopLevelWithHooks(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		CallerKeyval:     true,
+		Hooks: []pslog.Hook{func(e *pslog.Entry) bool {
+			e.Add("late", 1)
+			e.Delete("drop")
+			return true
+		}},
+		Redact: &pslog.Redaction{Keys: []string{"token"}},
+	})
+	pslog.WithGroup(logger, "http").Info("req", "token", "t", "drop", true)
+
+	want := `{"lvl":"info","msg":"req","http":{"token":"[REDACTED]","late":1},"fn":"TestWithGroupKeepsCallerTopLevelWithHooks"}`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Fatalf("got %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,274 @@
+package pslog
+
+import "sync"
+
+// Hook inspects a log entry before it is encoded. Hooks may change the level
+// or message, rewrite and add runtime fields through the Entry methods, or
+// return false to drop the entry. Level filtering and sampling have already
+// happened when a hook runs, so changing the level only affects rendering.
+//
+// The Entry is only valid for the duration of the call.
+type Hook func(e *Entry) bool
+
+// Entry is the mutable view of a log entry passed to hooks.
+type Entry struct {
+	// Level is the entry's level.
+	Level Level
+	// Message is the entry's message.
+	Message string
+	// Keyvals holds the runtime key/value pairs passed to the log call,
+	// preceded by the evaluated Valuer and Lazy fields bound via With. Use
+	// Set, Add and Delete to change them; the slice itself belongs to the
+	// caller until the first change.
+	Keyvals []any
+
+	static  []field
+	scratch []any
+	owned   bool
+	// bound lists, for the leading Keyvals pairs, the index of the dynamic
+	// field bound outside the logger's innermost group. After the hooks have
+	// run, those pairs move to outer and their group depths to depths.
+	bound  []int
+	outer  []any
+	depths []int
+	// stack holds the frames captured for console loggers, which print them
+	// below the line instead of as a field.
+	stack []string
+}
+
+// Static returns the value of the static field key added via With.
+func (e *Entry) Static(key string) (any, bool) {
+	for i := len(e.static) - 1; i >= 0; i-- {
+		if e.static[i].key == key {
+			return e.static[i].value, true
+		}
+	}
+	return nil, false
+}
+
+// StaticFields returns the static fields added via With as key/value pairs.
+// Static fields are pre-encoded and cannot be changed by hooks.
+func (e *Entry) StaticFields() []any {
+	if len(e.static) == 0 {
+		return nil
+	}
+	out := make([]any, 0, len(e.static)*2)
+	for _, f := range e.static {
+		out = append(out, f.key, f.value)
+	}
+	return out
+}
+
+// Get returns the runtime value stored under key.
+func (e *Entry) Get(key string) (any, bool) {
+	if idx := e.index(key); idx >= 0 {
+		return e.Keyvals[idx+1], true
+	}
+	return nil, false
+}
+
+// Set replaces the runtime value stored under key, appending the pair when
+// the key is not present.
+func (e *Entry) Set(key string, value any) {
+	e.own()
+	if idx := e.index(key); idx >= 0 {
+		e.Keyvals[idx+1] = value
+		return
+	}
+	e.Keyvals = append(e.Keyvals, key, value)
+	e.scratch = e.Keyvals
+}
+
+// Add appends key/value pairs to the entry.
+func (e *Entry) Add(keyvals ...any) {
+	if len(keyvals) == 0 {
+		return
+	}
+	e.own()
+	e.Keyvals = append(e.Keyvals, keyvals...)
+	e.scratch = e.Keyvals
+}
+
+// Delete removes every runtime pair stored under key.
+func (e *Entry) Delete(key string) {
+	if e.index(key) < 0 {
+		return
+	}
+	e.own()
+	kept := e.Keyvals[:0]
+	bound := e.bound[:0]
+	for i := 0; i < len(e.Keyvals); i += 2 {
+		if i+1 < len(e.Keyvals) && keyvalKeyIs(e.Keyvals[i], key) {
+			continue
+		}
+		if pair := i / 2; pair < len(e.bound) {
+			bound = append(bound, e.bound[pair])
+		}
+		kept = append(kept, e.Keyvals[i])
+		if i+1 < len(e.Keyvals) {
+			kept = append(kept, e.Keyvals[i+1])
+		}
+	}
+	e.Keyvals = kept
+	e.bound = bound
+}
+
+func (e *Entry) index(key string) int {
+	for i := 0; i+1 < len(e.Keyvals); i += 2 {
+		if keyvalKeyIs(e.Keyvals[i], key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// own copies Keyvals into the entry's scratch buffer so changes never touch
+// the caller's slice.
+func (e *Entry) own() {
+	if e.owned {
+		return
+	}
+	e.scratch = append(e.scratch[:0], e.Keyvals...)
+	e.Keyvals = e.scratch
+	e.owned = true
+}
+
+func keyvalKeyIs(v any, key string) bool {
+	k, ok := keyvalKey(v)
+	return ok && k == key
+}
+
+var entryPool = sync.Pool{
+	New: func() any { return new(Entry) },
+}
+
+// hookChain is resolved once when the adapter is built; loggers without hooks
+// carry a nil chain and select a write path that never consults it.
+type hookChain struct {
+	hooks []Hook
+}
+
+func newHookChain(hooks []Hook, stack *stackCapture, redact *redactor, errs *errorRenderer) *hookChain {
+	var kept []Hook
+	for _, hook := range hooks {
+		if hook != nil {
+			kept = append(kept, hook)
+		}
+	}
+	if stack != nil {
+		kept = append(kept, stack.hook)
+	}
+	if redact != nil {
+		kept = append(kept, redact.hook)
+	}
+	if errs != nil {
+		kept = append(kept, errs.hook)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return &hookChain{hooks: kept}
+}
+
+// with returns a copy of the chain, which may be nil, with hook appended.
+func (c *hookChain) with(hook Hook) *hookChain {
+	next := &hookChain{}
+	if c != nil {
+		next.hooks = append(next.hooks, c.hooks...)
+	}
+	next.hooks = append(next.hooks, hook)
+	return next
+}
+
+// run passes the entry through every hook and reports false when a hook drops
+// it.
+func (c *hookChain) run(e *Entry) bool {
+	for _, hook := range c.hooks {
+		if !hook(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// hooked reports whether entries must go through entry before they are
+// encoded. Emitters consult it when they are built to select their write path.
+func (b *loggerBase) hooked() bool {
+	return b.cfg.hooks != nil || len(b.dynamic) > 0
+}
+
+// entry prepares the Entry for the emitters' cold path. Dynamic fields bound
+// via With are evaluated and placed in front of the runtime keyvals before the
+// hooks run. It returns nil when a hook drops the entry; otherwise the caller
+// must release the Entry once the line has been encoded.
+func (b *loggerBase) entry(level Level, msg string, keyvals []any) *Entry {
+	e := entryPool.Get().(*Entry)
+	e.Level = level
+	e.Message = msg
+	e.Keyvals = keyvals
+	e.static = b.fields
+	e.owned = false
+	if len(b.dynamic) > 0 {
+		scratch := e.scratch[:0]
+		for i, f := range b.dynamic {
+			scratch = append(scratch, f.key, resolveValue(f.value))
+			if f.group < len(b.groups) {
+				e.bound = append(e.bound, i)
+			}
+		}
+		e.scratch = append(scratch, keyvals...)
+		e.Keyvals = e.scratch
+		e.owned = true
+	}
+	if b.cfg.hooks != nil && !b.cfg.hooks.run(e) {
+		releaseEntry(e)
+		return nil
+	}
+	if len(e.bound) > 0 {
+		b.splitOuter(e)
+	}
+	return e
+}
+
+// splitOuter moves the dynamic fields bound outside the innermost group out of
+// the runtime keyvals so grouped emitters can render them at the depth they
+// were bound at. When a hook replaced Keyvals and the leading pairs no longer
+// match, every pair is rendered as a runtime keyval.
+func (b *loggerBase) splitOuter(e *Entry) {
+	n := len(e.bound)
+	if len(e.Keyvals) < n*2 {
+		return
+	}
+	for j, idx := range e.bound {
+		if e.Keyvals[j*2] != b.dynamic[idx].key {
+			return
+		}
+	}
+	for _, idx := range e.bound {
+		e.depths = append(e.depths, b.dynamic[idx].group)
+	}
+	e.outer = e.Keyvals[: n*2 : n*2]
+	e.Keyvals = e.Keyvals[n*2:]
+}
+
+// outerFields returns the dynamic fields split off by splitOuter and the
+// group depth of each pair. It is safe to call on a nil Entry.
+func (e *Entry) outerFields() ([]any, []int) {
+	if e == nil {
+		return nil, nil
+	}
+	return e.outer, e.depths
+}
+
+func releaseEntry(e *Entry) {
+	clear(e.scratch[:cap(e.scratch)])
+	e.scratch = e.scratch[:0]
+	e.Keyvals = nil
+	e.static = nil
+	e.stack = nil
+	e.bound = e.bound[:0]
+	e.outer = nil
+	e.depths = e.depths[:0]
+	e.Message = ""
+	entryPool.Put(e)
+}
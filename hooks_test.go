@@ -0,0 +1,111 @@
+package pslog_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+func TestHooksMutateAndVeto(t *testing.T) {
+	variants := []struct {
+		name string
+		opts pslog.Options
+		want string
+	}{
+		{"json_plain", pslog.Options{Mode: pslog.ModeStructured, NoColor: true}, `{"lvl":"warn","msg":"user login","svc":"api","user":"alice","token":"[redacted]","env":"prod"}`},
+		{"json_color", pslog.Options{Mode: pslog.ModeStructured, ForceColor: true}, `{"lvl":"warn","msg":"user login","svc":"api","user":"alice","token":"[redacted]","env":"prod"}`},
+		{"console_plain", pslog.Options{Mode: pslog.ModeConsole, NoColor: true}, `WRN user login svc=api user=alice token=[redacted] env=prod`},
+		{"console_color", pslog.Options{Mode: pslog.ModeConsole, ForceColor: true}, `WRN user login svc=api user=alice token=[redacted] env=prod`},
+	}
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var sawStatic any
+			opts := variant.opts
+			opts.DisableTimestamp = true
+			opts.Hooks = []pslog.Hook{
+				func(e *pslog.Entry) bool {
+					return e.Message != "noisy"
+				},
+				func(e *pslog.Entry) bool {
+					sawStatic, _ = e.Static("svc")
+					if _, ok := e.Get("token"); ok {
+						e.Set("token", "[redacted]")
+						e.Level = pslog.WarnLevel
+						e.Message = "user " + e.Message
+					}
+					e.Delete("debug")
+					e.Add("env", "prod")
+					return true
+				},
+			}
+			logger := pslog.NewWithOptions(nil, &buf, opts).With("svc", "api")
+
+			keyvals := []any{"user", "alice", "token", "s3cr3t", "debug", true}
+			logger.Info("login", keyvals...)
+			logger.Info("noisy")
+
+			if got := stripANSI(strings.TrimSpace(buf.String())); got != variant.want {
+				t.Fatalf("unexpected output:\n got %s\nwant %s", got, variant.want)
+			}
+			if keyvals[3] != "s3cr3t" || len(keyvals) != 6 {
+				t.Fatalf("hooks must not modify the caller's keyvals: %v", keyvals)
+			}
+			if sawStatic != "api" {
+				t.Fatalf("expected hook to see static field, got %v", sawStatic)
+			}
+		})
+	}
+}
+
+func TestHooksSkipFilteredEntries(t *testing.T) {
+	calls := 0
+	logger := pslog.NewWithOptions(nil, io.Discard, pslog.Options{
+		Mode:     pslog.ModeStructured,
+		MinLevel: pslog.InfoLevel,
+		Hooks:    []pslog.Hook{func(*pslog.Entry) bool { calls++; return true }},
+	})
+	logger.Debug("filtered")
+	logger.Info("kept")
+	if calls != 1 {
+		t.Fatalf("expected hook to run once, ran %d times", calls)
+	}
+}
+
+func TestHooksStaticFields(t *testing.T) {
+	var got []any
+	logger := pslog.NewWithOptions(nil, io.Discard, pslog.Options{
+		Mode: pslog.ModeStructured,
+		Hooks: []pslog.Hook{func(e *pslog.Entry) bool {
+			got = e.StaticFields()
+			return true
+		}},
+	})
+	logger.With("a", 1).With("b", "two").Info("msg")
+	if len(got) != 4 || got[0] != "a" || got[1] != 1 || got[2] != "b" || got[3] != "two" {
+		t.Fatalf("unexpected static fields: %v", got)
+	}
+}
+
+func TestHooksAllocateZero(t *testing.T) {
+	logger := pslog.NewWithOptions(nil, io.Discard, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		Hooks: []pslog.Hook{func(e *pslog.Entry) bool {
+			_, ok := e.Get("key")
+			return ok
+		}},
+	})
+	keyvals := []any{"key", "value"}
+	logger.Info("warm", keyvals...)
+	allocs := testing.AllocsPerRun(1000, func() {
+		logger.Info("msg", keyvals...)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected 0 allocs/log with a read-only hook, got %.2f", allocs)
+	}
+}
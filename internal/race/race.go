@@ -0,0 +1,4 @@
+// Package race reports whether the race detector is enabled. Tests use it to
+// skip allocation checks: under -race, sync.Pool deliberately drops pooled
+// objects, so pooled hot paths allocate.
+package race
@@ -0,0 +1,270 @@
+package pslog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultJournalSocket is where systemd-journald listens for native protocol
+// datagrams.
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+// maxJournalFieldName is journald's limit on field name length.
+const maxJournalFieldName = 64
+
+// JournalOptions configures a JournalWriter.
+type JournalOptions struct {
+	// SocketPath is the journal socket. Defaults to DefaultJournalSocket.
+	SocketPath string
+
+	// Identifier is sent as SYSLOG_IDENTIFIER. Defaults to the executable
+	// name.
+	Identifier string
+
+	// CallerKey is the key holding caller information (Options.CallerKey).
+	// Its value becomes CODE_FILE and CODE_LINE when it has the form
+	// file:line and CODE_FUNC otherwise. Defaults to "fn". Writers opened by
+	// LoggerFromEnv from LOG_OUTPUT use the logger's resolved CallerKey.
+	CallerKey string
+}
+
+// JournalWriter sends pslog's structured JSON lines to systemd-journald using
+// the native protocol, so fields become journal fields rather than a JSON
+// blob in MESSAGE. Each entry carries MESSAGE, PRIORITY (levels map to
+// severities as for SyslogWriter), SYSLOG_IDENTIFIER and the code location
+// from the caller key. Other keys are flattened with dots, upper-cased, and
+// every character outside A-Z, 0-9 and '_' becomes '_'. Leading underscores,
+// reserved for trusted fields, are dropped; names that start with a digit or
+// clash with a field the writer sets get an "F_" prefix; names are cut to 64
+// characters. Lines that are not JSON objects are sent as MESSAGE at info
+// priority.
+//
+// Entries too large for a datagram are written to a sealed memfd, or an
+// unlinked file on /dev/shm where memfd_create is unavailable, and handed to
+// journald as a file descriptor. It is safe for
+// concurrent use.
+type JournalWriter struct {
+	addr *net.UnixAddr
+	opts JournalOptions
+
+	mu     sync.Mutex
+	conn   *net.UnixConn
+	closed bool
+	buf    []byte
+
+	// inlineLimit forces the file descriptor path for larger payloads when
+	// non-zero; the kernel's datagram limit applies otherwise.
+	inlineLimit int
+}
+
+// NewJournalWriter opens an unbound datagram socket for the journal. The
+// socket path is checked but journald does not need to be running yet;
+// writes fail until it is.
+func NewJournalWriter(opts JournalOptions) (*JournalWriter, error) {
+	if opts.SocketPath == "" {
+		opts.SocketPath = DefaultJournalSocket
+	}
+	if opts.Identifier == "" && len(os.Args) > 0 {
+		opts.Identifier = filepath.Base(os.Args[0])
+	}
+	if opts.CallerKey == "" {
+		opts.CallerKey = "fn"
+	}
+	if _, err := os.Stat(opts.SocketPath); err != nil {
+		return nil, fmt.Errorf("journal: %w", err)
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("journal: open socket: %w", err)
+	}
+	return &JournalWriter{
+		addr: &net.UnixAddr{Name: opts.SocketPath, Net: "unixgram"},
+		opts: opts,
+		conn: conn,
+	}, nil
+}
+
+// Write sends each line in p as one journal entry.
+func (w *JournalWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	var errs []error
+	for line := range bytes.SplitSeq(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		w.buf = w.appendEntry(w.buf[:0], line)
+		if err := w.send(w.buf); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the socket. It is safe to call more than once.
+func (w *JournalWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.conn.Close()
+}
+
+func (w *JournalWriter) send(payload []byte) error {
+	if w.inlineLimit == 0 || len(payload) <= w.inlineLimit {
+		_, _, err := w.conn.WriteMsgUnix(payload, nil, w.addr)
+		if err == nil {
+			return nil
+		}
+		if !journalPayloadTooLarge(err) {
+			return fmt.Errorf("journal: send: %w", err)
+		}
+	}
+	if err := sendJournalFD(w.conn, w.addr, payload); err != nil {
+		return fmt.Errorf("journal: send via file descriptor: %w", err)
+	}
+	return nil
+}
+
+// appendEntry renders line in the native protocol.
+func (w *JournalWriter) appendEntry(dst, line []byte) []byte {
+	entry, ok := parseStructuredLine(line, true)
+	if !ok {
+		dst = appendJournalField(dst, "MESSAGE", string(line))
+		return appendJournalField(dst, "PRIORITY", "6")
+	}
+	dst = appendJournalField(dst, "MESSAGE", entry.msg)
+	dst = appendJournalField(dst, "PRIORITY", strconv.Itoa(syslogSeverity(entry.level)))
+	if w.opts.Identifier != "" {
+		dst = appendJournalField(dst, "SYSLOG_IDENTIFIER", w.opts.Identifier)
+	}
+	for _, field := range entry.fields {
+		if field.name == w.opts.CallerKey {
+			dst = appendJournalCaller(dst, field.value)
+			continue
+		}
+		if name := journalFieldName(field.name); name != "" {
+			dst = appendJournalField(dst, name, field.value)
+		}
+	}
+	return dst
+}
+
+// appendJournalCaller maps a rendered caller onto CODE_FILE and CODE_LINE or
+// CODE_FUNC.
+func appendJournalCaller(dst []byte, caller string) []byte {
+	if i := strings.LastIndexByte(caller, ':'); i > 0 {
+		if _, err := strconv.Atoi(caller[i+1:]); err == nil {
+			dst = appendJournalField(dst, "CODE_FILE", caller[:i])
+			return appendJournalField(dst, "CODE_LINE", caller[i+1:])
+		}
+	}
+	return appendJournalField(dst, "CODE_FUNC", caller)
+}
+
+// appendJournalField writes NAME=value, or the length-prefixed binary form
+// when value spans lines.
+func appendJournalField(dst []byte, name, value string) []byte {
+	dst = append(dst, name...)
+	if !strings.Contains(value, "\n") {
+		dst = append(dst, '=')
+		dst = append(dst, value...)
+		return append(dst, '\n')
+	}
+	dst = append(dst, '\n')
+	dst = binary.LittleEndian.AppendUint64(dst, uint64(len(value)))
+	dst = append(dst, value...)
+	return append(dst, '\n')
+}
+
+// journalReservedFields are set by JournalWriter itself.
+var journalReservedFields = map[string]struct{}{
+	"MESSAGE": {}, "PRIORITY": {}, "SYSLOG_IDENTIFIER": {},
+	"CODE_FILE": {}, "CODE_LINE": {}, "CODE_FUNC": {},
+}
+
+// journalFieldName applies the sanitization rules documented on
+// JournalWriter. It returns "" when nothing usable is left.
+func journalFieldName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		default:
+			c = '_'
+		}
+		if c == '_' && b.Len() == 0 {
+			continue
+		}
+		b.WriteByte(c)
+	}
+	sanitized := b.String()
+	if sanitized == "" {
+		return ""
+	}
+	if _, reserved := journalReservedFields[sanitized]; reserved || sanitized[0] <= '9' {
+		sanitized = "F_" + sanitized
+	}
+	if len(sanitized) > maxJournalFieldName {
+		sanitized = sanitized[:maxJournalFieldName]
+	}
+	return sanitized
+}
+
+// setJournalCallerKey points every JournalWriter in w, including ones wrapped
+// by LoggerFromEnv, at key, so the logger's caller field still maps to
+// CODE_FILE and CODE_FUNC when Options.CallerKey is not the default.
+func setJournalCallerKey(w io.Writer, key string) {
+	switch v := w.(type) {
+	case *JournalWriter:
+		v.mu.Lock()
+		v.opts.CallerKey = key
+		v.mu.Unlock()
+	case *ownedOutput:
+		setJournalCallerKey(v.writer, key)
+	case *teeWriter:
+		for _, inner := range v.writers {
+			setJournalCallerKey(inner, key)
+		}
+	}
+}
+
+// openJournalOutput opens the LOG_OUTPUT forms journald and
+// journald:/path/to/socket; spec is what follows "journald".
+func openJournalOutput(spec string) (*JournalWriter, error) {
+	var opts JournalOptions
+	if path, ok := strings.CutPrefix(spec, ":"); ok {
+		opts.SocketPath = strings.TrimSpace(path)
+		if opts.SocketPath == "" {
+			return nil, errors.New("missing socket path")
+		}
+	}
+	return NewJournalWriter(opts)
+}
+
+// isJournalOutput reports whether path is journald or journald:<socket>, so
+// that files such as journald.log are still opened as files.
+func isJournalOutput(path string) (string, bool) {
+	spec, ok := cutPrefixFold(path, "journald")
+	return spec, ok && (spec == "" || spec[0] == ':')
+}
@@ -0,0 +1,75 @@
+//go:build linux
+
+package pslog
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// memfd_create flags and file seals; values from x/sys/unix.
+const (
+	mfdCloexec      = 0x1
+	mfdAllowSealing = 0x2
+	fAddSeals       = 0x409
+	fGetSeals       = 0x40a
+	fSealSeal       = 0x1
+	fSealShrink     = 0x2
+	fSealGrow       = 0x4
+	fSealWrite      = 0x8
+	journalSeals    = fSealSeal | fSealShrink | fSealGrow | fSealWrite
+)
+
+// memfdCreateTrap returns the memfd_create syscall number, which syscall
+// does not export on every architecture, or 0 when it is unknown.
+func memfdCreateTrap() uintptr {
+	switch runtime.GOARCH {
+	case "386":
+		return 356
+	case "amd64":
+		return 319
+	case "arm":
+		return 385
+	case "arm64", "loong64", "riscv64":
+		return 279
+	case "mips", "mipsle":
+		return 4354
+	case "mips64", "mips64le":
+		return 5314
+	case "ppc64", "ppc64le":
+		return 360
+	case "s390x":
+		return 350
+	}
+	return 0
+}
+
+// journalMemfd writes payload to a memfd and seals it against any further
+// change, so journald can map it without copying it first.
+func journalMemfd(payload []byte) (*os.File, error) {
+	trap := memfdCreateTrap()
+	if trap == 0 {
+		return nil, errors.ErrUnsupported
+	}
+	name, err := syscall.BytePtrFromString("pslog-journal")
+	if err != nil {
+		return nil, err
+	}
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(name)), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	file := os.NewFile(fd, "pslog-journal")
+	if _, err := file.Write(payload); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, fd, fAddSeals, journalSeals); errno != 0 {
+		_ = file.Close()
+		return nil, errno
+	}
+	return file, nil
+}
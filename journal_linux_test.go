@@ -0,0 +1,235 @@
+package pslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// journalListener stands in for journald's socket.
+func journalListener(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, path
+}
+
+// readJournalEntry receives one entry, following a passed file descriptor
+// when the datagram is empty, and decodes its fields.
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+	buf := make([]byte, 1<<16)
+	oob := make([]byte, 128)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	payload := buf[:n]
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		if err != nil || len(msgs) != 1 {
+			t.Fatalf("control message: %v", err)
+		}
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		if err != nil || len(fds) != 1 {
+			t.Fatalf("unix rights: %v", err)
+		}
+		file := os.NewFile(uintptr(fds[0]), "journal-payload")
+		defer file.Close()
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			t.Fatalf("seek: %v", err)
+		}
+		if payload, err = io.ReadAll(file); err != nil {
+			t.Fatalf("read passed file: %v", err)
+		}
+	}
+	fields := make(map[string]string)
+	for len(payload) > 0 {
+		nl := bytes.IndexByte(payload, '\n')
+		if nl < 0 {
+			t.Fatalf("unterminated field in %q", payload)
+		}
+		if eq := bytes.IndexByte(payload[:nl], '='); eq >= 0 {
+			fields[string(payload[:eq])] = string(payload[eq+1 : nl])
+			payload = payload[nl+1:]
+			continue
+		}
+		name := string(payload[:nl])
+		size := binary.LittleEndian.Uint64(payload[nl+1:])
+		start := nl + 1 + 8
+		fields[name] = string(payload[start : start+int(size)])
+		payload = payload[start+int(size)+1:]
+	}
+	return fields
+}
+
+func TestJournalWriterSendsNativeFields(t *testing.T) {
+	listener, path := journalListener(t)
+	w, err := NewJournalWriter(JournalOptions{SocketPath: path, Identifier: "api"})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer w.Close()
+	logger := NewWithOptions(context.Background(), w, Options{Mode: ModeStructured, NoColor: true, CallerKeyval: true})
+	logger.With("request.id", 42).Error("failed", "http", map[string]any{"status": 503}, "stack", "a\nb", "_trusted", "x", "1st", true, "priority", "high")
+
+	fields := readJournalEntry(t, listener)
+	want := map[string]string{
+		"MESSAGE":           "failed",
+		"PRIORITY":          "3",
+		"SYSLOG_IDENTIFIER": "api",
+		"REQUEST_ID":        "42",
+		"HTTP_STATUS":       "503",
+		"STACK":             "a\nb",
+		"TRUSTED":           "x",
+		"F_1ST":             "true",
+		"F_PRIORITY":        "high",
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Fatalf("field %s = %q, want %q (all fields %q)", key, fields[key], value, fields)
+		}
+	}
+	// In-package test frames count as internal, so only presence is checked.
+	if fields["CODE_FUNC"] == "" || len(fields) != len(want)+1 {
+		t.Fatalf("unexpected fields %q", fields)
+	}
+	caller := string(appendJournalCaller(nil, `C:\src\main.go:42`))
+	if caller != "CODE_FILE=C:\\src\\main.go\nCODE_LINE=42\n" {
+		t.Fatalf("unexpected file:line mapping %q", caller)
+	}
+}
+
+func TestJournalWriterKeepsUserKeysAsFields(t *testing.T) {
+	listener, path := journalListener(t)
+	w, err := NewJournalWriter(JournalOptions{SocketPath: path})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer w.Close()
+	logger := NewWithOptions(context.Background(), w, Options{Mode: ModeStructured, NoColor: true})
+	logger.Info("login", "time", "noon", "message", "from user", "level", "admin")
+
+	fields := readJournalEntry(t, listener)
+	want := map[string]string{
+		"MESSAGE":   "login",
+		"PRIORITY":  "6",
+		"TIME":      "noon",
+		"F_MESSAGE": "from user",
+		"LEVEL":     "admin",
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Fatalf("field %s = %q, want %q (all fields %q)", key, fields[key], value, fields)
+		}
+	}
+}
+
+func TestJournalWriterPassesLargePayloadsAsFile(t *testing.T) {
+	listener, path := journalListener(t)
+	w, err := NewJournalWriter(JournalOptions{SocketPath: path})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer w.Close()
+	w.inlineLimit = 64
+	big := strings.Repeat("x", 4096)
+	if _, err := w.Write([]byte(`{"lvl":"info","msg":"big","blob":"` + big + `"}` + "\nplain text\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	fields := readJournalEntry(t, listener)
+	if fields["MESSAGE"] != "big" || fields["BLOB"] != big || fields["PRIORITY"] != "6" {
+		t.Fatalf("unexpected large entry %q", fields["MESSAGE"])
+	}
+	if plain := readJournalEntry(t, listener); plain["MESSAGE"] != "plain text" || plain["PRIORITY"] != "6" {
+		t.Fatalf("unexpected plain entry %q", plain)
+	}
+}
+
+func TestJournalFieldName(t *testing.T) {
+	for name, want := range map[string]string{
+		"user":                  "USER",
+		"http.method":           "HTTP_METHOD",
+		"__cursor":              "CURSOR",
+		"9lives":                "F_9LIVES",
+		"MESSAGE":               "F_MESSAGE",
+		"ünïcode":               "N__CODE",
+		"___":                   "",
+		strings.Repeat("k", 70): strings.Repeat("K", 64),
+	} {
+		if got := journalFieldName(name); got != want {
+			t.Fatalf("journalFieldName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLoggerFromEnvJournaldOutput(t *testing.T) {
+	listener, path := journalListener(t)
+	t.Setenv("LOG_OUTPUT", "journald:"+path)
+	t.Setenv("LOG_MODE", "console")
+	logger := LoggerFromEnv(context.Background())
+	logger.Warn("disk low", "free", "5%")
+	fields := readJournalEntry(t, listener)
+	if fields["MESSAGE"] != "disk low" || fields["PRIORITY"] != "4" || fields["FREE"] != "5%" {
+		t.Fatalf("unexpected entry %q", fields)
+	}
+	if _, ok := fields["TS"]; ok {
+		t.Fatalf("timestamp must be left to journald, got %q", fields)
+	}
+	if err := Close(logger); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := openJournalOutput(":" + filepath.Join(t.TempDir(), "missing.sock")); err == nil {
+		t.Fatalf("expected a missing socket to fail")
+	}
+}
+
+func TestJournalMemfdIsSealed(t *testing.T) {
+	file, err := journalMemfd([]byte("MESSAGE=sealed\n"))
+	if err != nil {
+		t.Fatalf("memfd: %v", err)
+	}
+	defer file.Close()
+	seals, _, errno := syscall.Syscall(syscall.SYS_FCNTL, file.Fd(), fGetSeals, 0)
+	if errno != 0 {
+		t.Fatalf("get seals: %v", errno)
+	}
+	if seals != journalSeals {
+		t.Fatalf("seals = %#x, want %#x", seals, journalSeals)
+	}
+	if _, err := file.Write([]byte("more")); err == nil {
+		t.Fatal("expected writes to a sealed memfd to fail")
+	}
+}
+
+func TestLoggerFromEnvJournaldUsesCallerKey(t *testing.T) {
+	listener, path := journalListener(t)
+	t.Setenv("LOG_OUTPUT", "journald:"+path)
+	t.Setenv("LOG_CALLER_KEYVAL", "true")
+	t.Setenv("LOG_CALLER_KEY", "caller")
+	logger := LoggerFromEnv(context.Background())
+	defer Close(logger)
+	logger.Info("hello")
+	fields := readJournalEntry(t, listener)
+	// Frames inside package pslog are skipped, so the caller is the test
+	// runner; what matters is that it arrives as CODE_FUNC.
+	if fields["CODE_FUNC"] == "" {
+		t.Fatalf("expected the caller key to map to CODE_FUNC, got %q", fields)
+	}
+	if _, ok := fields["CALLER"]; ok {
+		t.Fatalf("caller must not be sent as a plain field, got %q", fields)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build unix && !linux
+
+package pslog
+
+import (
+	"errors"
+	"os"
+)
+
+// journalMemfd reports that memfd_create is Linux-only, so sendJournalFD
+// falls back to /dev/shm.
+func journalMemfd([]byte) (*os.File, error) {
+	return nil, errors.ErrUnsupported
+}
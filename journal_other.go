@@ -0,0 +1,14 @@
+//go:build !unix
+
+package pslog
+
+import (
+	"errors"
+	"net"
+)
+
+func journalPayloadTooLarge(error) bool { return false }
+
+func sendJournalFD(*net.UnixConn, *net.UnixAddr, []byte) error {
+	return errors.New("file descriptor passing is not supported on this platform")
+}
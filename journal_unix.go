@@ -0,0 +1,49 @@
+//go:build unix
+
+package pslog
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+)
+
+// journalPayloadTooLarge reports whether a send failed because the datagram
+// exceeds the socket's limit.
+func journalPayloadTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// sendJournalFD passes payload to journald as a file descriptor in an empty
+// datagram. Like sd-journal, it uses a sealed memfd and falls back to an
+// unlinked file on /dev/shm where memfd_create is unavailable; the payload
+// never touches disk.
+func sendJournalFD(conn *net.UnixConn, addr *net.UnixAddr, payload []byte) error {
+	file, err := journalMemfd(payload)
+	if err != nil {
+		if file, err = journalShmFile(payload); err != nil {
+			return err
+		}
+	}
+	defer file.Close()
+	_, _, err = conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), addr)
+	return err
+}
+
+// journalShmFile writes payload to an unlinked file on /dev/shm.
+func journalShmFile(payload []byte) (*os.File, error) {
+	file, err := os.CreateTemp("/dev/shm", "pslog-journal-")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(file.Name()); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if _, err := file.Write(payload); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
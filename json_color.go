@@ -11,6 +11,8 @@ import (
 
 type jsonColorEmitFunc func(l *jsonColorLogger, lw *lineWriter, level Level, msg string, keyvals []any)
 
+type jsonColorWriteFunc func(l *jsonColorLogger, origin *recordOrigin, level Level, msg string, keyvals []any)
+
 type jsonColorLogger struct {
 	base           loggerBase
 	palette        *ansi.Palette
@@ -23,7 +25,9 @@ type jsonColorLogger struct {
 	lineHint       *atomic.Int64
 	floatPolicy    NonFiniteFloatPolicy
 	verboseField   bool
+	groups         jsonGroupLayout
 	emit           jsonColorEmitFunc
+	write          jsonColorWriteFunc
 }
 
 func writeColoredJSONStringField(lw *lineWriter, first *bool, keyData []byte, value string, color string, trusted bool) {
@@ -59,7 +63,8 @@ func newJSONColorLogger(ctx context.Context, cfg coreConfig, opts Options) *json
 	}
 	owner := ownerToken(logger)
 	claimTimeCacheOwnership(cfg.timeCache, owner)
-	claimContextCancellation(ctx, cfg.writer, cfg.timeCache, owner)
+	cfg.sampler.claimOwnership(owner)
+	claimContextCancellation(ctx, cfg.writer, cfg.timeCache, cfg.sampler, owner)
 	logger.rebuildBasePayload()
 	return logger
 }
@@ -72,7 +77,7 @@ func (l *jsonColorLogger) Error(msg string, keyvals ...any) { l.log(ErrorLevel,
 
 func (l *jsonColorLogger) Fatal(msg string, keyvals ...any) {
 	l.log(FatalLevel, msg, keyvals...)
-	exitProcess()
+	l.base.cfg.fatalExit(ownerToken(l))
 }
 
 func (l *jsonColorLogger) Panic(msg string, keyvals ...any) {
@@ -88,8 +93,28 @@ func (l *jsonColorLogger) log(level Level, msg string, keyvals ...any) {
 	if !l.base.cfg.shouldLog(level) {
 		return
 	}
-	keyvals = l.base.maybeAddCaller(keyvals)
+	if l.base.cfg.sampler != nil && !l.base.cfg.sampler.allow(level, msg) {
+		return
+	}
+	l.write(l, nil, level, msg, keyvals)
+}
+
+func (l *jsonColorLogger) logRecord(origin *recordOrigin, level Level, msg string, keyvals []any) {
+	if !l.base.cfg.shouldLog(level) {
+		return
+	}
+	if l.base.cfg.sampler != nil && !l.base.cfg.sampler.allow(level, msg) {
+		return
+	}
+	l.write(l, origin, level, msg, keyvals)
+}
+
+// logDirect writes an entry for loggers without hooks or dynamic fields. It
+// runs after the level and sampling checks.
+func (l *jsonColorLogger) logDirect(origin *recordOrigin, level Level, msg string, keyvals []any) {
 	lw := acquireLineWriter(l.base.cfg.writer)
+	lw.origin = origin
+	keyvals = l.base.maybeAddCaller(lw, keyvals)
 	lw.autoFlush = false
 	if l.floatPolicy != NonFiniteFloatAsString {
 		lw.floatPolicy = l.floatPolicy
@@ -101,15 +126,54 @@ func (l *jsonColorLogger) log(level Level, msg string, keyvals ...any) {
 	}
 	l.emit(l, lw, level, msg, keyvals)
 	lw.finishLine()
-	lw.commit()
+	if async := l.base.cfg.async; async != nil {
+		async.commitLine(level, lw)
+	} else {
+		lw.commit()
+	}
 	if l.lineHint != nil {
 		l.lineHint.Store(int64(lw.lastLineLength()))
 	}
 	releaseLineWriter(lw)
 }
 
+// logEntry is the cold path selected instead of logDirect when Options.Hooks
+// is set or the logger carries Lazy or Valuer fields bound via With.
+func (l *jsonColorLogger) logEntry(origin *recordOrigin, level Level, msg string, keyvals []any) {
+	entry := l.base.entry(level, msg, keyvals)
+	if entry == nil {
+		return
+	}
+	level, msg = entry.Level, entry.Message
+	lw := acquireLineWriter(l.base.cfg.writer)
+	lw.origin = origin
+	lw.entry = entry
+	keyvals = l.base.maybeAddCaller(lw, entry.Keyvals)
+	lw.autoFlush = false
+	if l.floatPolicy != NonFiniteFloatAsString {
+		lw.floatPolicy = l.floatPolicy
+	}
+	if l.lineHint != nil {
+		if hint := l.lineHint.Load(); hint > 0 {
+			lw.preallocate(int(hint))
+		}
+	}
+	l.emit(l, lw, level, msg, keyvals)
+	lw.finishLine()
+	if async := l.base.cfg.async; async != nil {
+		async.commitLine(level, lw)
+	} else {
+		lw.commit()
+	}
+	if l.lineHint != nil {
+		l.lineHint.Store(int64(lw.lastLineLength()))
+	}
+	releaseLineWriter(lw)
+	releaseEntry(entry)
+}
+
 func (l *jsonColorLogger) With(keyvals ...any) Logger {
-	fields := collectFields(keyvals)
+	fields := collectFields(keyvals, l.base.cfg.errors != nil)
 	if len(fields) == 0 {
 		return l
 	}
@@ -165,17 +229,79 @@ func (l *jsonColorLogger) LogLevelFromEnv(key string) Logger {
 	return l
 }
 
+func (l *jsonColorLogger) Named(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	clone.base.withName(name)
+	clone.rebuildBasePayload()
+	return &clone
+}
+
+func (l *jsonColorLogger) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	clone.base.withGroup(name)
+	clone.rebuildBasePayload()
+	return &clone
+}
+
+func (l *jsonColorLogger) core() *loggerBase { return &l.base }
+
+func (l *jsonColorLogger) withCoreConfig(adjust func(*coreConfig)) Logger {
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	adjust(&clone.base.cfg)
+	clone.rebuildBasePayload()
+	return &clone
+}
+
 func (l *jsonColorLogger) Close() error {
-	return closeLoggerRuntime(l.base.cfg.writer, l.base.cfg.timeCache, ownerToken(l))
+	return closeLoggerRuntime(l.base.cfg.writer, l.base.cfg.timeCache, l.base.cfg.sampler, ownerToken(l))
 }
 
 func (l *jsonColorLogger) rebuildBasePayload() {
-	l.basePayload = encodeBaseJSONColor(l.base.fields, l.palette, l.floatPolicy)
+	if len(l.base.groups) > 0 {
+		l.groups = encodeJSONGroups(l.base.payloadFields(), l.base.groups,
+			func(fields []field) []byte { return encodeBaseJSONColor(fields, l.palette, l.floatPolicy) },
+			func(name string) []byte { return append(makeColoredKey(name, l.palette.Key, true), '{') })
+		l.basePayload = nil
+	} else {
+		l.groups = jsonGroupLayout{}
+		l.basePayload = encodeBaseJSONColor(l.base.payloadFields(), l.palette, l.floatPolicy)
+	}
 	l.hasBasePayload = len(l.basePayload) > 0
 	if l.base.cfg.includeLogLevel {
 		l.base.cfg.logLevelValue = LevelString(l.base.cfg.currentLevel())
 	}
 	l.emit = selectJSONColorEmit(l.base.cfg, l.hasBasePayload)
+	if len(l.base.groups) > 0 {
+		l.emit = emitJSONColorGrouped
+	}
+	l.write = (*jsonColorLogger).logDirect
+	if l.base.hooked() {
+		l.write = (*jsonColorLogger).logEntry
+	}
 }
 
 func selectJSONColorEmit(cfg coreConfig, hasStaticFields bool) jsonColorEmitFunc {
@@ -203,14 +329,47 @@ func selectJSONColorEmit(cfg coreConfig, hasStaticFields bool) jsonColorEmitFunc
 	}
 }
 
+// emitJSONColorGrouped serves loggers with open groups. Runtime keyvals are
+// written inside the innermost group; the caller and loglevel fields stay at
+// the top level.
+func emitJSONColorGrouped(l *jsonColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
+	levelColor := colorForLevel(level, l.palette)
+	levelLabel := LevelString(level)
+	estimate := 2 + l.groups.size +
+		len(l.lvlKeyData) + len(levelLabel) + len(levelColor) + len(ansi.Reset)
+	if msg != "" {
+		estimate += len(l.msgKeyData) + len(msg) + len(l.palette.Message) + len(ansi.Reset)
+	}
+	if n := len(keyvals); n > 0 {
+		estimate += n*8 + n*(len(l.palette.Key)+len(ansi.Reset))
+	}
+	lw.reserve(estimate)
+	lw.writeByte('{')
+	first := true
+	if l.base.cfg.includeTimestamp {
+		writeColoredJSONStringField(lw, &first, l.tsKeyData, l.base.cfg.timestampFor(lw), l.palette.Timestamp, l.base.cfg.timestampTrusted)
+	}
+	writeColoredJSONStringField(lw, &first, l.lvlKeyData, levelLabel, levelColor, true)
+	if msg != "" {
+		appendKeyDataWithFirst(lw, &first, l.msgKeyData)
+		writeColoredJSONString(lw, msg, l.palette.Message)
+	}
+	l.groups.write(lw, &first, keyvals, l.palette)
+	writeRuntimeJSONFieldsColor(lw, &first, lw.caller, l.palette)
+	if l.base.cfg.includeLogLevel {
+		writeColoredJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelLabel(), l.palette.String, true)
+	}
+	lw.writeByte('}')
+}
+
 func emitJSONColorTimestampLogLevelWithStaticFields(l *jsonColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelColor := colorForLevel(level, l.palette)
 	levelLabel := LevelString(level)
 	estimate := 2 + len(l.basePayload) + len(l.lvlKeyData) + len(levelLabel) +
 		len(levelColor) + len(ansi.Reset) +
 		len(l.tsKeyData) + len(timestamp) + len(l.palette.Timestamp) + len(ansi.Reset) +
-		len(l.logLevelKey) + len(l.base.cfg.logLevelValue) + len(l.palette.String) + len(ansi.Reset)
+		len(l.logLevelKey) + len(l.base.cfg.logLevelLabel()) + len(l.palette.String) + len(ansi.Reset)
 	if msg != "" {
 		estimate += len(l.msgKeyData) + len(msg) + len(l.palette.Message) + len(ansi.Reset)
 	}
@@ -229,18 +388,18 @@ func emitJSONColorTimestampLogLevelWithStaticFields(l *jsonColorLogger, lw *line
 	lw.writeBytes(l.basePayload)
 	first = false
 	writeRuntimeJSONFieldsColor(lw, &first, keyvals, l.palette)
-	writeColoredJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelValue, l.palette.String, true)
+	writeColoredJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelLabel(), l.palette.String, true)
 	lw.writeByte('}')
 }
 
 func emitJSONColorTimestampLogLevelNoStaticFields(l *jsonColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelColor := colorForLevel(level, l.palette)
 	levelLabel := LevelString(level)
 	estimate := 2 + len(l.lvlKeyData) + len(levelLabel) +
 		len(levelColor) + len(ansi.Reset) +
 		len(l.tsKeyData) + len(timestamp) + len(l.palette.Timestamp) + len(ansi.Reset) +
-		len(l.logLevelKey) + len(l.base.cfg.logLevelValue) + len(l.palette.String) + len(ansi.Reset)
+		len(l.logLevelKey) + len(l.base.cfg.logLevelLabel()) + len(l.palette.String) + len(ansi.Reset)
 	if msg != "" {
 		estimate += len(l.msgKeyData) + len(msg) + len(l.palette.Message) + len(ansi.Reset)
 	}
@@ -257,12 +416,12 @@ func emitJSONColorTimestampLogLevelNoStaticFields(l *jsonColorLogger, lw *lineWr
 		writeColoredJSONString(lw, msg, l.palette.Message)
 	}
 	writeRuntimeJSONFieldsColor(lw, &first, keyvals, l.palette)
-	writeColoredJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelValue, l.palette.String, true)
+	writeColoredJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelLabel(), l.palette.String, true)
 	lw.writeByte('}')
 }
 
 func emitJSONColorTimestampWithStaticFields(l *jsonColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelColor := colorForLevel(level, l.palette)
 	levelLabel := LevelString(level)
 	estimate := 2 + len(l.basePayload) + len(l.lvlKeyData) + len(levelLabel) +
@@ -290,7 +449,7 @@ func emitJSONColorTimestampWithStaticFields(l *jsonColorLogger, lw *lineWriter,
 }
 
 func emitJSONColorTimestampNoStaticFields(l *jsonColorLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelColor := colorForLevel(level, l.palette)
 	levelLabel := LevelString(level)
 	estimate := 2 + len(l.lvlKeyData) + len(levelLabel) +
@@ -320,7 +479,7 @@ func emitJSONColorLogLevelWithStaticFields(l *jsonColorLogger, lw *lineWriter, l
 	levelLabel := LevelString(level)
 	estimate := 2 + len(l.basePayload) + len(l.lvlKeyData) + len(levelLabel) +
 		len(levelColor) + len(ansi.Reset) +
-		len(l.logLevelKey) + len(l.base.cfg.logLevelValue) + len(l.palette.String) + len(ansi.Reset)
+		len(l.logLevelKey) + len(l.base.cfg.logLevelLabel()) + len(l.palette.String) + len(ansi.Reset)
 	if msg != "" {
 		estimate += len(l.msgKeyData) + len(msg) + len(l.palette.Message) + len(ansi.Reset)
 	}
@@ -338,7 +497,7 @@ func emitJSONColorLogLevelWithStaticFields(l *jsonColorLogger, lw *lineWriter, l
 	lw.writeBytes(l.basePayload)
 	first = false
 	writeRuntimeJSONFieldsColor(lw, &first, keyvals, l.palette)
-	writeColoredJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelValue, l.palette.String, true)
+	writeColoredJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelLabel(), l.palette.String, true)
 	lw.writeByte('}')
 }
 
@@ -347,7 +506,7 @@ func emitJSONColorLogLevelNoStaticFields(l *jsonColorLogger, lw *lineWriter, lev
 	levelLabel := LevelString(level)
 	estimate := 2 + len(l.lvlKeyData) + len(levelLabel) +
 		len(levelColor) + len(ansi.Reset) +
-		len(l.logLevelKey) + len(l.base.cfg.logLevelValue) + len(l.palette.String) + len(ansi.Reset)
+		len(l.logLevelKey) + len(l.base.cfg.logLevelLabel()) + len(l.palette.String) + len(ansi.Reset)
 	if msg != "" {
 		estimate += len(l.msgKeyData) + len(msg) + len(l.palette.Message) + len(ansi.Reset)
 	}
@@ -363,7 +522,7 @@ func emitJSONColorLogLevelNoStaticFields(l *jsonColorLogger, lw *lineWriter, lev
 		writeColoredJSONString(lw, msg, l.palette.Message)
 	}
 	writeRuntimeJSONFieldsColor(lw, &first, keyvals, l.palette)
-	writeColoredJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelValue, l.palette.String, true)
+	writeColoredJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelLabel(), l.palette.String, true)
 	lw.writeByte('}')
 }
 
@@ -621,6 +780,12 @@ func writeRuntimeJSONValueColor(lw *lineWriter, value any, palette *ansi.Palette
 		writePTJSONStringTrustedColored(lw, palette.Timestamp, lw.formatTimeRFC3339(v))
 	case time.Duration:
 		writePTJSONStringTrustedColored(lw, palette.String, lw.formatDuration(v))
+	case keyvalGroup:
+		writeJSONGroupColor(lw, v, palette)
+	case ObjectMarshaler, ArrayMarshaler:
+		writeJSONMarshaler(lw, v, palette)
+	case deferred:
+		writeRuntimeJSONValueColor(lw, v.resolve(), palette)
 	case stringer:
 		s := v.String()
 		color := palette.String
@@ -655,6 +820,13 @@ func writeRuntimeJSONValueColor(lw *lineWriter, value any, palette *ansi.Palette
 	}
 }
 
+func writeJSONGroupColor(lw *lineWriter, group keyvalGroup, palette *ansi.Palette) {
+	lw.writeByte('{')
+	first := true
+	writeRuntimeJSONFieldsColor(lw, &first, group, palette)
+	lw.writeByte('}')
+}
+
 func colorForLevel(level Level, palette *ansi.Palette) string {
 	switch level {
 	case TraceLevel:
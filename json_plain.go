@@ -8,6 +8,8 @@ import (
 
 type jsonPlainEmitFunc func(l *jsonPlainLogger, lw *lineWriter, level Level, msg string, keyvals []any)
 
+type jsonPlainWriteFunc func(l *jsonPlainLogger, origin *recordOrigin, level Level, msg string, keyvals []any)
+
 type jsonPlainLogger struct {
 	base           loggerBase
 	tsKeyData      []byte
@@ -19,7 +21,9 @@ type jsonPlainLogger struct {
 	lineHint       *atomic.Int64
 	floatPolicy    NonFiniteFloatPolicy
 	verboseField   bool
+	groups         jsonGroupLayout
 	emit           jsonPlainEmitFunc
+	write          jsonPlainWriteFunc
 }
 
 func appendKeyDataWithFirst(lw *lineWriter, first *bool, keyData []byte) {
@@ -64,7 +68,8 @@ func newJSONPlainLogger(ctx context.Context, cfg coreConfig, opts Options) *json
 	}
 	owner := ownerToken(logger)
 	claimTimeCacheOwnership(cfg.timeCache, owner)
-	claimContextCancellation(ctx, cfg.writer, cfg.timeCache, owner)
+	cfg.sampler.claimOwnership(owner)
+	claimContextCancellation(ctx, cfg.writer, cfg.timeCache, cfg.sampler, owner)
 	logger.rebuildBasePayload()
 	return logger
 }
@@ -77,7 +82,7 @@ func (l *jsonPlainLogger) Error(msg string, keyvals ...any) { l.log(ErrorLevel,
 
 func (l *jsonPlainLogger) Fatal(msg string, keyvals ...any) {
 	l.log(FatalLevel, msg, keyvals...)
-	exitProcess()
+	l.base.cfg.fatalExit(ownerToken(l))
 }
 
 func (l *jsonPlainLogger) Panic(msg string, keyvals ...any) {
@@ -93,8 +98,62 @@ func (l *jsonPlainLogger) log(level Level, msg string, keyvals ...any) {
 	if !l.base.cfg.shouldLog(level) {
 		return
 	}
-	keyvals = l.base.maybeAddCaller(keyvals)
+	if l.base.cfg.sampler != nil && !l.base.cfg.sampler.allow(level, msg) {
+		return
+	}
+	l.write(l, nil, level, msg, keyvals)
+}
+
+func (l *jsonPlainLogger) logRecord(origin *recordOrigin, level Level, msg string, keyvals []any) {
+	if !l.base.cfg.shouldLog(level) {
+		return
+	}
+	if l.base.cfg.sampler != nil && !l.base.cfg.sampler.allow(level, msg) {
+		return
+	}
+	l.write(l, origin, level, msg, keyvals)
+}
+
+// logDirect writes an entry for loggers without hooks or dynamic fields. It
+// runs after the level and sampling checks.
+func (l *jsonPlainLogger) logDirect(origin *recordOrigin, level Level, msg string, keyvals []any) {
+	lw := acquireLineWriter(l.base.cfg.writer)
+	lw.origin = origin
+	keyvals = l.base.maybeAddCaller(lw, keyvals)
+	lw.autoFlush = false
+	if l.floatPolicy != NonFiniteFloatAsString {
+		lw.floatPolicy = l.floatPolicy
+	}
+	if l.lineHint != nil {
+		if hint := l.lineHint.Load(); hint > 0 {
+			lw.preallocate(int(hint))
+		}
+	}
+	l.emit(l, lw, level, msg, keyvals)
+	lw.finishLine()
+	if async := l.base.cfg.async; async != nil {
+		async.commitLine(level, lw)
+	} else {
+		lw.commit()
+	}
+	if l.lineHint != nil {
+		l.lineHint.Store(int64(lw.lastLineLength()))
+	}
+	releaseLineWriter(lw)
+}
+
+// logEntry is the cold path selected instead of logDirect when Options.Hooks
+// is set or the logger carries Lazy or Valuer fields bound via With.
+func (l *jsonPlainLogger) logEntry(origin *recordOrigin, level Level, msg string, keyvals []any) {
+	entry := l.base.entry(level, msg, keyvals)
+	if entry == nil {
+		return
+	}
+	level, msg = entry.Level, entry.Message
 	lw := acquireLineWriter(l.base.cfg.writer)
+	lw.origin = origin
+	lw.entry = entry
+	keyvals = l.base.maybeAddCaller(lw, entry.Keyvals)
 	lw.autoFlush = false
 	if l.floatPolicy != NonFiniteFloatAsString {
 		lw.floatPolicy = l.floatPolicy
@@ -106,15 +165,20 @@ func (l *jsonPlainLogger) log(level Level, msg string, keyvals ...any) {
 	}
 	l.emit(l, lw, level, msg, keyvals)
 	lw.finishLine()
-	lw.commit()
+	if async := l.base.cfg.async; async != nil {
+		async.commitLine(level, lw)
+	} else {
+		lw.commit()
+	}
 	if l.lineHint != nil {
 		l.lineHint.Store(int64(lw.lastLineLength()))
 	}
 	releaseLineWriter(lw)
+	releaseEntry(entry)
 }
 
 func (l *jsonPlainLogger) With(keyvals ...any) Logger {
-	fields := collectFields(keyvals)
+	fields := collectFields(keyvals, l.base.cfg.errors != nil)
 	if len(fields) == 0 {
 		return l
 	}
@@ -170,17 +234,79 @@ func (l *jsonPlainLogger) LogLevelFromEnv(key string) Logger {
 	return l
 }
 
+func (l *jsonPlainLogger) Named(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	clone.base.withName(name)
+	clone.rebuildBasePayload()
+	return &clone
+}
+
+func (l *jsonPlainLogger) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	clone.base.withGroup(name)
+	clone.rebuildBasePayload()
+	return &clone
+}
+
+func (l *jsonPlainLogger) core() *loggerBase { return &l.base }
+
+func (l *jsonPlainLogger) withCoreConfig(adjust func(*coreConfig)) Logger {
+	clone := *l
+	if l.lineHint != nil {
+		hint := l.lineHint.Load()
+		clone.lineHint = new(atomic.Int64)
+		clone.lineHint.Store(hint)
+	}
+	clone.base = l.base.clone()
+	adjust(&clone.base.cfg)
+	clone.rebuildBasePayload()
+	return &clone
+}
+
 func (l *jsonPlainLogger) Close() error {
-	return closeLoggerRuntime(l.base.cfg.writer, l.base.cfg.timeCache, ownerToken(l))
+	return closeLoggerRuntime(l.base.cfg.writer, l.base.cfg.timeCache, l.base.cfg.sampler, ownerToken(l))
 }
 
 func (l *jsonPlainLogger) rebuildBasePayload() {
-	l.basePayload = encodeBaseJSONPlain(l.base.fields, l.floatPolicy)
+	if len(l.base.groups) > 0 {
+		l.groups = encodeJSONGroups(l.base.payloadFields(), l.base.groups,
+			func(fields []field) []byte { return encodeBaseJSONPlain(fields, l.floatPolicy) },
+			func(name string) []byte { return append(makeKeyData(name, true), '{') })
+		l.basePayload = nil
+	} else {
+		l.groups = jsonGroupLayout{}
+		l.basePayload = encodeBaseJSONPlain(l.base.payloadFields(), l.floatPolicy)
+	}
 	l.hasBasePayload = len(l.basePayload) > 0
 	if l.base.cfg.includeLogLevel {
 		l.base.cfg.logLevelValue = LevelString(l.base.cfg.currentLevel())
 	}
 	l.emit = selectJSONPlainEmit(l.base.cfg, l.hasBasePayload)
+	if len(l.base.groups) > 0 {
+		l.emit = emitJSONPlainGrouped
+	}
+	l.write = (*jsonPlainLogger).logDirect
+	if l.base.hooked() {
+		l.write = (*jsonPlainLogger).logEntry
+	}
 }
 
 func selectJSONPlainEmit(cfg coreConfig, hasStaticFields bool) jsonPlainEmitFunc {
@@ -208,13 +334,41 @@ func selectJSONPlainEmit(cfg coreConfig, hasStaticFields bool) jsonPlainEmitFunc
 	}
 }
 
+// emitJSONPlainGrouped serves loggers with open groups. Runtime keyvals are
+// written inside the innermost group; the caller and loglevel fields stay at
+// the top level.
+func emitJSONPlainGrouped(l *jsonPlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
+	levelLabel := LevelString(level)
+	estimate := 2 + l.groups.size + len(keyvals)*8 +
+		len(l.lvlKeyData) + len(levelLabel)
+	if msg != "" {
+		estimate += len(l.msgKeyData) + len(msg)
+	}
+	lw.reserve(estimate)
+	lw.writeByte('{')
+	first := true
+	if l.base.cfg.includeTimestamp {
+		writeJSONStringField(lw, &first, l.tsKeyData, l.base.cfg.timestampFor(lw), l.base.cfg.timestampTrusted)
+	}
+	writeJSONStringField(lw, &first, l.lvlKeyData, levelLabel, true)
+	if msg != "" {
+		writeJSONStringField(lw, &first, l.msgKeyData, msg, false)
+	}
+	l.groups.write(lw, &first, keyvals, nil)
+	writeRuntimeJSONFieldsPlain(lw, &first, lw.caller)
+	if l.base.cfg.includeLogLevel {
+		writeJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelLabel(), true)
+	}
+	lw.writeByte('}')
+}
+
 func emitJSONPlainTimestampLogLevelWithStaticFields(l *jsonPlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelLabel := LevelString(level)
 	estimate := 2 + len(l.basePayload) + len(keyvals)*8 +
 		len(l.tsKeyData) + len(timestamp) +
 		len(l.lvlKeyData) + len(levelLabel) +
-		len(l.logLevelKey) + len(l.base.cfg.logLevelValue)
+		len(l.logLevelKey) + len(l.base.cfg.logLevelLabel())
 	if msg != "" {
 		estimate += len(l.msgKeyData) + len(msg)
 	}
@@ -229,17 +383,17 @@ func emitJSONPlainTimestampLogLevelWithStaticFields(l *jsonPlainLogger, lw *line
 	lw.writeBytes(l.basePayload)
 	first = false
 	writeRuntimeJSONFieldsPlain(lw, &first, keyvals)
-	writeJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelValue, true)
+	writeJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelLabel(), true)
 	lw.writeByte('}')
 }
 
 func emitJSONPlainTimestampLogLevelNoStaticFields(l *jsonPlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelLabel := LevelString(level)
 	estimate := 2 + len(keyvals)*8 +
 		len(l.tsKeyData) + len(timestamp) +
 		len(l.lvlKeyData) + len(levelLabel) +
-		len(l.logLevelKey) + len(l.base.cfg.logLevelValue)
+		len(l.logLevelKey) + len(l.base.cfg.logLevelLabel())
 	if msg != "" {
 		estimate += len(l.msgKeyData) + len(msg)
 	}
@@ -252,12 +406,12 @@ func emitJSONPlainTimestampLogLevelNoStaticFields(l *jsonPlainLogger, lw *lineWr
 		writeJSONStringField(lw, &first, l.msgKeyData, msg, false)
 	}
 	writeRuntimeJSONFieldsPlain(lw, &first, keyvals)
-	writeJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelValue, true)
+	writeJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelLabel(), true)
 	lw.writeByte('}')
 }
 
 func emitJSONPlainTimestampWithStaticFields(l *jsonPlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelLabel := LevelString(level)
 	estimate := 2 + len(l.basePayload) + len(keyvals)*8 +
 		len(l.tsKeyData) + len(timestamp) +
@@ -280,7 +434,7 @@ func emitJSONPlainTimestampWithStaticFields(l *jsonPlainLogger, lw *lineWriter,
 }
 
 func emitJSONPlainTimestampNoStaticFields(l *jsonPlainLogger, lw *lineWriter, level Level, msg string, keyvals []any) {
-	timestamp := l.base.cfg.timestamp()
+	timestamp := l.base.cfg.timestampFor(lw)
 	levelLabel := LevelString(level)
 	estimate := 2 + len(keyvals)*8 +
 		len(l.tsKeyData) + len(timestamp) +
@@ -304,7 +458,7 @@ func emitJSONPlainLogLevelWithStaticFields(l *jsonPlainLogger, lw *lineWriter, l
 	levelLabel := LevelString(level)
 	estimate := 2 + len(l.basePayload) + len(keyvals)*8 +
 		len(l.lvlKeyData) + len(levelLabel) +
-		len(l.logLevelKey) + len(l.base.cfg.logLevelValue)
+		len(l.logLevelKey) + len(l.base.cfg.logLevelLabel())
 	if msg != "" {
 		estimate += len(l.msgKeyData) + len(msg)
 	}
@@ -318,7 +472,7 @@ func emitJSONPlainLogLevelWithStaticFields(l *jsonPlainLogger, lw *lineWriter, l
 	lw.writeBytes(l.basePayload)
 	first = false
 	writeRuntimeJSONFieldsPlain(lw, &first, keyvals)
-	writeJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelValue, true)
+	writeJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelLabel(), true)
 	lw.writeByte('}')
 }
 
@@ -326,7 +480,7 @@ func emitJSONPlainLogLevelNoStaticFields(l *jsonPlainLogger, lw *lineWriter, lev
 	levelLabel := LevelString(level)
 	estimate := 2 + len(keyvals)*8 +
 		len(l.lvlKeyData) + len(levelLabel) +
-		len(l.logLevelKey) + len(l.base.cfg.logLevelValue)
+		len(l.logLevelKey) + len(l.base.cfg.logLevelLabel())
 	if msg != "" {
 		estimate += len(l.msgKeyData) + len(msg)
 	}
@@ -338,7 +492,7 @@ func emitJSONPlainLogLevelNoStaticFields(l *jsonPlainLogger, lw *lineWriter, lev
 		writeJSONStringField(lw, &first, l.msgKeyData, msg, false)
 	}
 	writeRuntimeJSONFieldsPlain(lw, &first, keyvals)
-	writeJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelValue, true)
+	writeJSONStringField(lw, &first, l.logLevelKey, l.base.cfg.logLevelLabel(), true)
 	lw.writeByte('}')
 }
 
@@ -209,6 +209,8 @@ func writeRuntimeValuePlainInline(lw *lineWriter, value any) bool {
 	case time.Duration:
 		writePTJSONStringTrusted(lw, lw.formatDuration(v))
 		return true
+	case ObjectMarshaler, ArrayMarshaler:
+		return false
 	case stringer:
 		s := v.String()
 		if stringTrustedASCII(s) {
@@ -296,6 +298,8 @@ func writeRuntimeValueColorInline(lw *lineWriter, value any, color string) bool
 	case time.Duration:
 		writePTJSONStringTrustedColored(lw, color, lw.formatDuration(v))
 		return true
+	case ObjectMarshaler, ArrayMarshaler:
+		return false
 	case stringer:
 		s := v.String()
 		if stringTrustedASCII(s) {
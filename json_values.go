@@ -122,6 +122,12 @@ func writeJSONValuePlain(w *lineWriter, value any) {
 		writePTJSONString(w, v)
 	case TrustedString:
 		writePTJSONStringTrusted(w, string(v))
+	case keyvalGroup:
+		writeJSONGroupPlain(w, v)
+	case ObjectMarshaler, ArrayMarshaler:
+		writeJSONMarshaler(w, v, nil)
+	case deferred:
+		writeJSONValuePlain(w, v.resolve())
 	case stringer:
 		writeJSONStringPlain(w, v.String())
 	case error:
@@ -194,6 +200,16 @@ func writeJSONValueColored(w *lineWriter, value any, color string) {
 		writePTJSONStringColored(w, color, v)
 	case TrustedString:
 		writePTJSONStringTrustedColored(w, color, string(v))
+	case keyvalGroup:
+		w.writeString(color)
+		writeJSONGroupPlain(w, v)
+		w.writeString(ansi.Reset)
+	case ObjectMarshaler, ArrayMarshaler:
+		w.writeString(color)
+		writeJSONMarshaler(w, v, nil)
+		w.writeString(ansi.Reset)
+	case deferred:
+		writeJSONValueColored(w, v.resolve(), color)
 	case stringer:
 		writePTJSONStringColored(w, color, v.String())
 	case error:
@@ -272,6 +288,13 @@ func writeJSONValueColored(w *lineWriter, value any, color string) {
 	}
 }
 
+func writeJSONGroupPlain(w *lineWriter, group keyvalGroup) {
+	w.writeByte('{')
+	first := true
+	writeRuntimeJSONFieldsPlain(w, &first, group)
+	w.writeByte('}')
+}
+
 func writeJSONStringTo(w *lineWriter, s string) {
 	w.writeByte('"')
 	start := 0
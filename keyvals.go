@@ -31,3 +31,8 @@ func Keyvals(keyvals ...any) []any {
 	}
 	return dst
 }
+
+// keyvalGroup is a nested set of key/value pairs. JSON emitters render it as an
+// object using the same key rules as runtime keyvals; adapters use it to carry
+// grouped attributes (for example slog groups) through the variadic API.
+type keyvalGroup []any
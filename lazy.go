@@ -0,0 +1,125 @@
+package pslog
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Lazy defers computing a field value until an entry is actually written.
+// The function runs after level filtering, sampling and hooks have let the
+// entry through, so expensive values cost nothing on disabled levels:
+//
+//	logger.Debug("state", "dump", pslog.Lazy(func() any { return s.Dump() }))
+//
+// Bound via With, a Lazy value is computed once, when the first entry that
+// includes it is written, and reused afterwards. Use Valuer for values that
+// must be recomputed on every entry.
+type Lazy func() any
+
+// Valuer is a dynamic field value. Bound via With, it is evaluated again for
+// every written entry, which suits values such as uptime or goroutine counts:
+//
+//	logger = logger.With("goroutines", pslog.Valuer(func() any { return runtime.NumGoroutine() }))
+//
+// Dynamic fields are rendered after the logger's static fields of the same
+// group and before the runtime keyvals of the log call. A field bound before
+// WithGroup stays outside the group, like a static field would.
+type Valuer func() any
+
+// LogValue implements slog.LogValuer so log/slog handlers evaluate the value
+// lazily as well.
+func (f Lazy) LogValue() slog.Value { return slog.AnyValue(f.resolve()) }
+
+// LogValue implements slog.LogValuer.
+func (f Valuer) LogValue() slog.Value { return slog.AnyValue(f.resolve()) }
+
+// deferred is implemented by values computed when an entry is encoded.
+type deferred interface {
+	resolve() any
+}
+
+func (f Lazy) resolve() any {
+	if f == nil {
+		return nil
+	}
+	return f()
+}
+
+func (f Valuer) resolve() any {
+	if f == nil {
+		return nil
+	}
+	return f()
+}
+
+// onceValue memoises a Lazy bound via With.
+type onceValue struct {
+	once  sync.Once
+	fn    Lazy
+	value any
+}
+
+func (o *onceValue) resolve() any {
+	o.once.Do(o.load)
+	return o.value
+}
+
+func (o *onceValue) load() {
+	o.value = resolveValue(o.fn.resolve())
+	o.fn = nil
+}
+
+// resolveValue evaluates v until it is no longer a deferred value.
+func resolveValue(v any) any {
+	for {
+		d, ok := v.(deferred)
+		if !ok {
+			return v
+		}
+		v = d.resolve()
+	}
+}
+
+// dynamicField is a Lazy or Valuer pair bound via With. The key is boxed once
+// so placing it in front of the runtime keyvals does not allocate.
+type dynamicField struct {
+	key   any
+	value any
+	// group is the number of WithGroup groups enclosing the field.
+	group int
+}
+
+// splitDynamicFields moves Lazy and Valuer values out of fields so they are
+// not pre-encoded into the static payload.
+func splitDynamicFields(fields []field) ([]field, []dynamicField) {
+	idx := -1
+	for i := range fields {
+		if _, ok := fields[i].value.(deferred); ok {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fields, nil
+	}
+	static := fields[:idx:idx]
+	var dynamic []dynamicField
+	for _, f := range fields[idx:] {
+		var value any
+		switch v := f.value.(type) {
+		case Lazy:
+			value = &onceValue{fn: v}
+		case Valuer, *onceValue:
+			value = v
+		default:
+			static = append(static, f)
+			continue
+		}
+		var key any = f.key
+		if f.trustedKey {
+			key = TrustedString(f.key)
+		}
+		dynamic = append(dynamic, dynamicField{key: key, value: value, group: f.group})
+	}
+	return static, dynamic
+}
@@ -0,0 +1,159 @@
+package pslog_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+func TestLazySkippedWhenDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	calls := 0
+	lazy := pslog.Lazy(func() any {
+		calls++
+		return "expensive"
+	})
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, MinLevel: pslog.InfoLevel, DisableTimestamp: true, NoColor: true})
+	logger.Debug("skipped", "dump", lazy)
+	if calls != 0 {
+		t.Fatalf("lazy value evaluated for a disabled level: %d calls", calls)
+	}
+	logger.Info("written", "dump", lazy)
+	if calls != 1 {
+		t.Fatalf("expected one evaluation, got %d", calls)
+	}
+	if got := strings.TrimSpace(buf.String()); got != `{"lvl":"info","msg":"written","dump":"expensive"}` {
+		t.Fatalf("unexpected output: %s", got)
+	}
+}
+
+func TestLazyAndValuerAcrossVariants(t *testing.T) {
+	variants := []struct {
+		name string
+		opts pslog.Options
+		want []string
+	}{
+		{"json_plain", pslog.Options{Mode: pslog.ModeStructured, NoColor: true}, []string{
+			`{"lvl":"info","msg":"tick","svc":"api","cfg":"loaded","seq":1,"n":1.5}`,
+			`{"lvl":"info","msg":"tick","svc":"api","cfg":"loaded","seq":2,"n":"x y"}`,
+		}},
+		{"json_color", pslog.Options{Mode: pslog.ModeStructured, ForceColor: true}, []string{
+			`{"lvl":"info","msg":"tick","svc":"api","cfg":"loaded","seq":1,"n":1.5}`,
+			`{"lvl":"info","msg":"tick","svc":"api","cfg":"loaded","seq":2,"n":"x y"}`,
+		}},
+		{"console_plain", pslog.Options{Mode: pslog.ModeConsole, NoColor: true}, []string{
+			`INF tick svc=api cfg=loaded seq=1 n=1.5`,
+			`INF tick svc=api cfg=loaded seq=2 n="x y"`,
+		}},
+		{"console_color", pslog.Options{Mode: pslog.ModeConsole, ForceColor: true}, []string{
+			`INF tick svc=api cfg=loaded seq=1 n=1.5`,
+			`INF tick svc=api cfg=loaded seq=2 n="x y"`,
+		}},
+	}
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := variant.opts
+			opts.DisableTimestamp = true
+			seq, loads := 0, 0
+			logger := pslog.NewWithOptions(nil, &buf, opts).With(
+				"cfg", pslog.Lazy(func() any { loads++; return "loaded" }),
+				"svc", "api",
+				"seq", pslog.Valuer(func() any { seq++; return seq }),
+			)
+			logger.Info("tick", "n", pslog.Lazy(func() any { return 1.5 }))
+			logger.Info("tick", "n", pslog.Valuer(func() any { return "x y" }))
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != len(variant.want) {
+				t.Fatalf("expected %d lines, got %q", len(variant.want), buf.String())
+			}
+			for i, line := range lines {
+				if got := stripANSI(line); got != variant.want[i] {
+					t.Fatalf("line %d:\n got %s\nwant %s", i, got, variant.want[i])
+				}
+			}
+			if loads != 1 {
+				t.Fatalf("expected With-bound Lazy to be evaluated once, got %d", loads)
+			}
+		})
+	}
+}
+
+func TestValuerSeenByHooksAndRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	var seen any
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		Hooks: []pslog.Hook{func(e *pslog.Entry) bool {
+			seen, _ = e.Get("uptime")
+			return true
+		}},
+		Redact: &pslog.Redaction{Keys: []string{"token"}},
+	})
+	tokenCalls := 0
+	logger.With(
+		"uptime", pslog.Valuer(func() any { return "42s" }),
+		"token", pslog.Valuer(func() any { tokenCalls++; return "secret" }),
+	).Info("msg")
+	if seen != "42s" {
+		t.Fatalf("hook saw %v, want evaluated value", seen)
+	}
+	if got := strings.TrimSpace(buf.String()); got != `{"lvl":"info","msg":"msg","uptime":"42s","token":"[REDACTED]"}` {
+		t.Fatalf("unexpected output: %s", got)
+	}
+	if tokenCalls != 1 {
+		t.Fatalf("expected one evaluation of the redacted Valuer, got %d", tokenCalls)
+	}
+}
+
+func TestDynamicFieldsKeepTheirGroup(t *testing.T) {
+	variants := []struct {
+		name string
+		opts pslog.Options
+		want []string
+	}{
+		{"json_plain", pslog.Options{Mode: pslog.ModeStructured, NoColor: true}, []string{
+			`{"lvl":"info","msg":"x","req":"r1"}`,
+			`{"lvl":"info","msg":"x","req":"r1","http":{"method":"GET","seq":1,"tls":{"ver":"1.3"}}}`,
+		}},
+		{"json_color", pslog.Options{Mode: pslog.ModeStructured, ForceColor: true}, []string{
+			`{"lvl":"info","msg":"x","req":"r1"}`,
+			`{"lvl":"info","msg":"x","req":"r1","http":{"method":"GET","seq":1,"tls":{"ver":"1.3"}}}`,
+		}},
+		{"console_plain", pslog.Options{Mode: pslog.ModeConsole, NoColor: true}, []string{
+			`INF x req=r1`,
+			`INF x http.method=GET req=r1 http.seq=1 http.tls.ver=1.3`,
+		}},
+		{"console_color", pslog.Options{Mode: pslog.ModeConsole, ForceColor: true}, []string{
+			`INF x req=r1`,
+			`INF x http.method=GET req=r1 http.seq=1 http.tls.ver=1.3`,
+		}},
+	}
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := variant.opts
+			opts.DisableTimestamp = true
+			seq := 0
+			logger := pslog.NewWithOptions(nil, &buf, opts).With("req", pslog.Lazy(func() any { return "r1" }))
+			pslog.WithGroup(logger, "http").Info("x")
+			http := pslog.WithGroup(logger, "http").With("method", "GET", "seq", pslog.Valuer(func() any { seq++; return seq }))
+			pslog.WithGroup(http, "tls").Info("x", "ver", "1.3")
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != len(variant.want) {
+				t.Fatalf("expected %d lines, got %q", len(variant.want), buf.String())
+			}
+			for i, line := range lines {
+				if got := stripANSI(line); got != variant.want[i] {
+					t.Fatalf("line %d:\n got %s\nwant %s", i, got, variant.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package pslog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelHandler is an http.Handler that exposes a LevelVar for inspection and
+// runtime changes, typically mounted on an internal admin mux.
+//
+// GET returns the current level as JSON:
+//
+//	{"level":"info"}
+//
+// PUT changes it. The new level is read from a JSON body
+// ({"level":"debug","ttl":"10m"}) or from the level and ttl query parameters,
+// and accepts the same strings as ParseLevel. A positive ttl reverts the level
+// to its value before the first unexpired change once the duration elapses;
+// a change without ttl is permanent and cancels any pending revert.
+//
+// Every change, including automatic reverts, is logged as a
+// `logger.level.changed` entry. The entry is pinned to InfoLevel so it is
+// emitted even when the new level would otherwise filter it.
+type LevelHandler struct {
+	level  *LevelVar
+	logger Logger
+
+	mu        sync.Mutex
+	revert    *time.Timer
+	revertGen uint64
+	revertTo  Level
+	revertAt  time.Time
+}
+
+// NewLevelHandler returns a LevelHandler controlling level. Changes are
+// reported through logger; a nil logger disables the change entries.
+func NewLevelHandler(level *LevelVar, logger Logger) *LevelHandler {
+	if logger == nil {
+		logger = noopLogger{}
+	}
+	return &LevelHandler{
+		level:  level,
+		logger: logger.LogLevel(InfoLevel),
+	}
+}
+
+type levelHandlerRequest struct {
+	Level string `json:"level"`
+	TTL   string `json:"ttl,omitempty"`
+}
+
+type levelHandlerResponse struct {
+	Level    string `json:"level"`
+	Previous string `json:"previous,omitempty"`
+	RevertTo string `json:"revert_to,omitempty"`
+	RevertAt string `json:"revert_at,omitempty"`
+}
+
+// ServeHTTP implements http.Handler.
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.level == nil {
+		http.Error(w, "no level configured", http.StatusServiceUnavailable)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		h.mu.Lock()
+		resp := h.responseLocked("")
+		h.mu.Unlock()
+		writeLevelResponse(w, http.StatusOK, resp)
+	case http.MethodHead:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPut:
+		h.servePut(w, r)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *LevelHandler) servePut(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeLevelRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	level, ok := ParseLevel(req.Level)
+	if !ok {
+		http.Error(w, "invalid level "+strconv.Quote(req.Level), http.StatusBadRequest)
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil || ttl < 0 {
+			http.Error(w, "invalid ttl "+strconv.Quote(req.TTL), http.StatusBadRequest)
+			return
+		}
+	}
+
+	h.mu.Lock()
+	previous := h.level.Level()
+	baseline := previous
+	if h.revert != nil {
+		h.revert.Stop()
+		h.revert = nil
+		baseline = h.revertTo
+	}
+	h.level.Set(level)
+	if ttl > 0 {
+		h.revertTo = baseline
+		h.revertAt = time.Now().Add(ttl)
+		h.revertGen++
+		gen := h.revertGen
+		h.revert = time.AfterFunc(ttl, func() { h.expire(gen) })
+	} else {
+		h.revertAt = time.Time{}
+	}
+	resp := h.responseLocked(LevelString(previous))
+	h.mu.Unlock()
+
+	keyvals := []any{"from", LevelString(previous), "to", LevelString(level), "reason", "http"}
+	if ttl > 0 {
+		keyvals = append(keyvals, "ttl", ttl, "revert_to", LevelString(baseline))
+	}
+	if r.RemoteAddr != "" {
+		keyvals = append(keyvals, "remote", r.RemoteAddr)
+	}
+	h.logger.Info("logger.level.changed", keyvals...)
+	writeLevelResponse(w, http.StatusOK, resp)
+}
+
+// expire reverts the level when gen still identifies the pending revert. A
+// timer that was superseded by a later PUT is ignored.
+func (h *LevelHandler) expire(gen uint64) {
+	h.mu.Lock()
+	if h.revert == nil || h.revertGen != gen {
+		h.mu.Unlock()
+		return
+	}
+	h.revert = nil
+	h.revertAt = time.Time{}
+	previous := h.level.Level()
+	target := h.revertTo
+	h.level.Set(target)
+	h.mu.Unlock()
+	h.logger.Info("logger.level.changed", "from", LevelString(previous), "to", LevelString(target), "reason", "ttl_expired")
+}
+
+func (h *LevelHandler) responseLocked(previous string) levelHandlerResponse {
+	resp := levelHandlerResponse{Level: LevelString(h.level.Level()), Previous: previous}
+	if h.revert != nil {
+		resp.RevertTo = LevelString(h.revertTo)
+		resp.RevertAt = h.revertAt.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
+
+func decodeLevelRequest(r *http.Request) (levelHandlerRequest, error) {
+	query := r.URL.Query()
+	req := levelHandlerRequest{Level: query.Get("level"), TTL: query.Get("ttl")}
+	if r.Body == nil {
+		return req, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 4096))
+	if err != nil {
+		return req, err
+	}
+	trimmed := strings.TrimSpace(string(body))
+	switch {
+	case trimmed == "":
+	case strings.HasPrefix(trimmed, "{"):
+		var decoded levelHandlerRequest
+		if err := json.Unmarshal([]byte(trimmed), &decoded); err != nil {
+			return req, err
+		}
+		if decoded.Level != "" {
+			req.Level = decoded.Level
+		}
+		if decoded.TTL != "" {
+			req.TTL = decoded.TTL
+		}
+	default:
+		req.Level = trimmed
+	}
+	return req, nil
+}
+
+func writeLevelResponse(w http.ResponseWriter, status int, resp levelHandlerResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,154 @@
+package pslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pkt.systems/pslog"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func serveLevel(t *testing.T, h http.Handler, method, target, body string) (int, map[string]string) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid json response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, payload
+}
+
+func TestLevelHandlerGetAndPut(t *testing.T) {
+	var out syncBuffer
+	level := pslog.NewLevelVar(pslog.InfoLevel)
+	logger := pslog.NewWithOptions(nil, &out, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true, LevelVar: level})
+	h := pslog.NewLevelHandler(level, logger)
+
+	code, payload := serveLevel(t, h, http.MethodGet, "/loglevel", "")
+	if code != http.StatusOK || payload["level"] != "info" {
+		t.Fatalf("unexpected GET response %d %v", code, payload)
+	}
+
+	code, payload = serveLevel(t, h, http.MethodPut, "/loglevel", `{"level":"WARNING"}`)
+	if code != http.StatusOK || payload["level"] != "warn" || payload["previous"] != "info" {
+		t.Fatalf("unexpected PUT response %d %v", code, payload)
+	}
+	if level.Level() != pslog.WarnLevel {
+		t.Fatalf("expected level var to be updated, got %v", level)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out.String())), &entry); err != nil {
+		t.Fatalf("invalid change entry %q: %v", out.String(), err)
+	}
+	if entry["msg"] != "logger.level.changed" || entry["from"] != "info" || entry["to"] != "warn" {
+		t.Fatalf("unexpected change entry: %v", entry)
+	}
+
+	code, _ = serveLevel(t, h, http.MethodPut, "/loglevel?level=debug", "")
+	if code != http.StatusOK || level.Level() != pslog.DebugLevel {
+		t.Fatalf("expected query parameter PUT to apply, got %d %v", code, level)
+	}
+	code, _ = serveLevel(t, h, http.MethodPut, "/loglevel", "error")
+	if code != http.StatusOK || level.Level() != pslog.ErrorLevel {
+		t.Fatalf("expected plain-text PUT to apply, got %d %v", code, level)
+	}
+	if !strings.Contains(out.String(), `"to":"error"`) {
+		t.Fatalf("expected change entry to be emitted despite error level: %q", out.String())
+	}
+}
+
+func TestLevelHandlerRejectsInvalidInput(t *testing.T) {
+	level := pslog.NewLevelVar(pslog.InfoLevel)
+	h := pslog.NewLevelHandler(level, nil)
+
+	if code, _ := serveLevel(t, h, http.MethodPut, "/", `{"level":"loud"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid level, got %d", code)
+	}
+	if code, _ := serveLevel(t, h, http.MethodPut, "/", `{"level":"debug","ttl":"soon"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid ttl, got %d", code)
+	}
+	if code, _ := serveLevel(t, h, http.MethodPost, "/", `{"level":"debug"}`); code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for POST, got %d", code)
+	}
+	if level.Level() != pslog.InfoLevel {
+		t.Fatalf("expected level to be unchanged, got %v", level)
+	}
+}
+
+func TestLevelHandlerTTLReverts(t *testing.T) {
+	var out syncBuffer
+	level := pslog.NewLevelVar(pslog.InfoLevel)
+	logger := pslog.NewWithOptions(nil, &out, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true, LevelVar: level})
+	h := pslog.NewLevelHandler(level, logger)
+
+	_, payload := serveLevel(t, h, http.MethodPut, "/", `{"level":"trace","ttl":"1h"}`)
+	if payload["revert_to"] != "info" || payload["revert_at"] == "" {
+		t.Fatalf("expected pending revert in response, got %v", payload)
+	}
+	// A second timed change keeps the original baseline.
+	_, payload = serveLevel(t, h, http.MethodPut, "/", `{"level":"debug","ttl":"20ms"}`)
+	if payload["revert_to"] != "info" {
+		t.Fatalf("expected revert target to stay info, got %v", payload)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for level.Level() != pslog.InfoLevel {
+		if time.Now().After(deadline) {
+			t.Fatalf("level was not reverted, still %v", level)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for !strings.Contains(out.String(), `"reason":"ttl_expired"`) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected revert entry, got %q", out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, payload = serveLevel(t, h, http.MethodGet, "/", ""); payload["revert_at"] != "" {
+		t.Fatalf("expected no pending revert after expiry, got %v", payload)
+	}
+}
+
+func TestLevelHandlerHeadSendsNoBody(t *testing.T) {
+	handler := pslog.NewLevelHandler(pslog.NewLevelVar(pslog.InfoLevel), nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/level", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("unexpected content type %q", got)
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("expected an empty body, got %q", rec.Body.String())
+	}
+}
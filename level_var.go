@@ -0,0 +1,47 @@
+package pslog
+
+import "sync/atomic"
+
+// LevelVar is a Level that can be changed at runtime and shared by many
+// loggers. Pass it in Options.LevelVar and every logger built from those
+// options, as well as every logger derived from them via With or
+// WithLogLevel, consults the current value on each entry. Loggers rendering
+// a `loglevel` field report the live value.
+//
+// Calling LogLevel on a logger detaches the derived logger from the LevelVar
+// and pins it to the requested level. The zero value is DebugLevel, matching
+// the zero value of Options.MinLevel. A LevelVar is safe for concurrent use.
+type LevelVar struct {
+	v atomic.Int32
+}
+
+// NewLevelVar returns a LevelVar initialised to level.
+func NewLevelVar(level Level) *LevelVar {
+	v := new(LevelVar)
+	v.Set(level)
+	return v
+}
+
+// Level returns the current level.
+func (v *LevelVar) Level() Level {
+	return Level(v.v.Load())
+}
+
+// Set changes the level for every logger sharing v.
+func (v *LevelVar) Set(level Level) {
+	v.v.Store(int32(level))
+}
+
+// String returns the canonical name of the current level.
+func (v *LevelVar) String() string {
+	return LevelString(v.Level())
+}
+
+// levelVarBase returns the level v holds when an adapter is built, which is
+// when NamedLevels overrides apply; see Options.NamedLevels.
+func levelVarBase(v *LevelVar) Level {
+	if v == nil {
+		return InfoLevel
+	}
+	return v.Level()
+}
@@ -0,0 +1,106 @@
+package pslog_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+func TestLevelVarSharedAcrossDerivedLoggers(t *testing.T) {
+	variants := []struct {
+		name string
+		opts pslog.Options
+	}{
+		{"json_plain", pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true}},
+		{"json_color", pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, ForceColor: true}},
+		{"console_plain", pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, NoColor: true}},
+		{"console_color", pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, ForceColor: true}},
+	}
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			level := pslog.NewLevelVar(pslog.InfoLevel)
+			opts := variant.opts
+			opts.LevelVar = level
+			root := pslog.NewWithOptions(nil, &buf, opts)
+			child := root.With("svc", "api").WithLogLevel()
+
+			child.Debug("hidden")
+			if buf.Len() != 0 {
+				t.Fatalf("expected debug to be filtered, got %q", buf.String())
+			}
+
+			level.Set(pslog.DebugLevel)
+			child.Debug("visible")
+			root.Debug("root visible")
+			lines := strings.Split(strings.TrimSpace(stripANSI(buf.String())), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("expected two lines after raising verbosity, got %q", buf.String())
+			}
+			if !strings.Contains(lines[0], `loglevel=debug`) && !strings.Contains(lines[0], `"loglevel":"debug"`) {
+				t.Fatalf("expected live loglevel value, got %q", lines[0])
+			}
+
+			buf.Reset()
+			level.Set(pslog.ErrorLevel)
+			child.Warn("hidden")
+			if buf.Len() != 0 {
+				t.Fatalf("expected warn to be filtered, got %q", buf.String())
+			}
+			child.Error("shown")
+			if out := stripANSI(buf.String()); !strings.Contains(out, `loglevel=error`) && !strings.Contains(out, `"loglevel":"error"`) {
+				t.Fatalf("expected live loglevel value error, got %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestLevelVarLogLevelDetaches(t *testing.T) {
+	var buf bytes.Buffer
+	level := pslog.NewLevelVar(pslog.ErrorLevel)
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true, LevelVar: level})
+	pinned := logger.LogLevel(pslog.InfoLevel)
+
+	pinned.Info("pinned")
+	if buf.Len() == 0 {
+		t.Fatalf("expected pinned logger to ignore the shared level")
+	}
+	buf.Reset()
+	level.Set(pslog.TraceLevel)
+	pinned.Debug("still filtered")
+	if buf.Len() != 0 {
+		t.Fatalf("expected pinned logger to keep InfoLevel, got %q", buf.String())
+	}
+}
+
+func TestLevelVarFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	level := pslog.NewLevelVar(pslog.DebugLevel)
+	var buf bytes.Buffer
+	logger := pslog.LoggerFromEnv(nil,
+		pslog.WithEnvOptions(pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true, LevelVar: level}),
+		pslog.WithEnvWriter(&buf),
+	)
+	if level.Level() != pslog.WarnLevel {
+		t.Fatalf("expected LOG_LEVEL to seed the LevelVar, got %v", level)
+	}
+	logger.Info("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("expected info to be filtered, got %q", buf.String())
+	}
+}
+
+func TestLevelVarDisabledPathAllocatesZero(t *testing.T) {
+	level := pslog.NewLevelVar(pslog.ErrorLevel)
+	logger := pslog.NewWithOptions(nil, io.Discard, pslog.Options{Mode: pslog.ModeStructured, LevelVar: level}).With("svc", "api")
+	keyvals := []any{"key", "value", "n", 123}
+	allocs := testing.AllocsPerRun(1000, func() {
+		logger.Debug("msg", keyvals...)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected 0 allocs on the disabled path, got %.2f", allocs)
+	}
+}
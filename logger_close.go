@@ -25,25 +25,27 @@ func claimTimeCacheOwnership(cache *timeCache, owner uintptr) {
 	cacheOwners.LoadOrStore(cache, owner)
 }
 
-func claimContextCancellation(ctx context.Context, writer io.Writer, cache *timeCache, owner uintptr) {
+func claimContextCancellation(ctx context.Context, writer io.Writer, cache *timeCache, sampler *sampler, owner uintptr) {
 	if owner == 0 || ctx == nil || ctx.Done() == nil {
 		return
 	}
-	if cache == nil && !writerNeedsOwnedClose(writer) {
+	if cache == nil && sampler == nil && !writerNeedsOwnedClose(writer) {
 		return
 	}
 	stop := context.AfterFunc(ctx, func() {
-		_ = closeLoggerRuntime(writer, cache, owner)
+		_ = closeLoggerRuntime(writer, cache, sampler, owner)
 	})
 	contextCancelOwners.Store(owner, stop)
 }
 
-func closeLoggerRuntime(writer io.Writer, cache *timeCache, owner uintptr) error {
+func closeLoggerRuntime(writer io.Writer, cache *timeCache, sampler *sampler, owner uintptr) error {
 	if stop, ok := contextCancelOwners.LoadAndDelete(owner); ok {
 		if stopFn, ok := stop.(func() bool); ok && stopFn != nil {
 			stopFn()
 		}
 	}
+	// The final sampling summary goes out before the output is closed.
+	sampler.stop(owner)
 	if cache != nil {
 		if claimed, ok := cacheOwners.Load(cache); ok && claimed == owner {
 			cache.Close()
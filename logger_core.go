@@ -2,13 +2,19 @@ package pslog
 
 import (
 	"io"
+	"slices"
+	"strings"
 	"time"
 )
 
+const loggerNameKey = "logger"
+
 type field struct {
 	key        string
 	value      any
 	trustedKey bool
+	// group is the number of WithGroup groups enclosing the field.
+	group int
 }
 
 // errorMessage wraps an error string so it still satisfies the error interface.
@@ -17,7 +23,10 @@ type errorMessage string
 
 func (e errorMessage) Error() string { return string(e) }
 
-func collectFields(keyvals []any) []field {
+// collectFields turns With keyvals into fields. richErrors keeps the error
+// passed to a bare With(err) for ErrorModeRich; otherwise only its message is
+// retained, so the logger does not pin the error for its lifetime.
+func collectFields(keyvals []any, richErrors bool) []field {
 	if len(keyvals) == 0 {
 		return nil
 	}
@@ -25,9 +34,13 @@ func collectFields(keyvals []any) []field {
 	// Special-case a single error value so callers can write With(err).
 	if len(keyvals) == 1 {
 		if err, ok := keyvals[0].(error); ok {
+			var value any = errorMessage(err.Error())
+			if richErrors {
+				value = capturedError{msg: err.Error(), err: err}
+			}
 			return []field{{
 				key:        "error",
-				value:      errorMessage(err.Error()),
+				value:      value,
 				trustedKey: true,
 			}}
 		}
@@ -94,6 +107,9 @@ type coreConfig struct {
 	writer           io.Writer
 	minLevel         Level
 	forcedLevel      *Level
+	levelVar         *LevelVar
+	levelVarBase     Level
+	nameLevel        *Level
 	includeLogLevel  bool
 	logLevelValue    string
 	includeTimestamp bool
@@ -104,7 +120,17 @@ type coreConfig struct {
 	timestampTrusted bool
 	includeCaller    bool
 	callerKey        string
-	callerKeyTrusted bool
+	callerKeyValue   any
+	callerMode       CallerMode
+	callerSkip       int
+	name             string
+	namedLevels      map[string]Level
+	sampler          *sampler
+	hooks            *hookChain
+	redactor         *redactor
+	errors           *errorRenderer
+	async            *AsyncWriter
+	fatal            *fatalConfig
 }
 
 func (c coreConfig) clone() coreConfig {
@@ -134,16 +160,62 @@ func (c coreConfig) shouldLog(level Level) bool {
 	if effective == Disabled {
 		return false
 	}
+	if c.levelVar != nil {
+		return effective >= c.varLevel()
+	}
 	return effective >= c.minLevel
 }
 
+// varLevel returns the LevelVar's level. Named loggers with a NamedLevels
+// override use the override instead while the LevelVar still holds the level
+// it had when the adapter was built.
+func (c coreConfig) varLevel() Level {
+	level := c.levelVar.Level()
+	if c.nameLevel != nil && level == c.levelVarBase {
+		return *c.nameLevel
+	}
+	return level
+}
+
 func (c coreConfig) currentLevel() Level {
 	if c.forcedLevel != nil {
 		return *c.forcedLevel
 	}
+	if c.levelVar != nil {
+		return c.varLevel()
+	}
 	return c.minLevel
 }
 
+// namedLevel returns the level configured for name or its closest dotted
+// ancestor.
+func (c coreConfig) namedLevel(name string) (Level, bool) {
+	if len(c.namedLevels) == 0 {
+		return 0, false
+	}
+	for name != "" {
+		if level, ok := c.namedLevels[name]; ok {
+			return level, true
+		}
+		idx := strings.LastIndexByte(name, '.')
+		if idx < 0 {
+			break
+		}
+		name = name[:idx]
+	}
+	return 0, false
+}
+
+// logLevelLabel returns the value rendered in the `loglevel` field. Loggers
+// following a LevelVar report its live value; everything else uses the label
+// resolved when the logger was derived.
+func (c coreConfig) logLevelLabel() string {
+	if c.levelVar != nil && c.forcedLevel == nil {
+		return LevelString(c.varLevel())
+	}
+	return c.logLevelValue
+}
+
 func (c coreConfig) timestamp() string {
 	if !c.includeTimestamp {
 		return ""
@@ -151,19 +223,39 @@ func (c coreConfig) timestamp() string {
 	if c.timeCache != nil {
 		return c.timeCache.Current()
 	}
-	now := time.Now()
+	return c.formatTime(time.Now())
+}
+
+// timestampFor returns the timestamp of the line lw is writing: the record's
+// own time for entries bridged from log/slog, the current time otherwise.
+func (c *coreConfig) timestampFor(lw *lineWriter) string {
+	if lw.origin == nil || !c.includeTimestamp {
+		return c.timestamp()
+	}
+	return c.formatTime(lw.origin.time)
+}
+
+func (c coreConfig) formatTime(t time.Time) string {
 	if c.useUTC {
-		now = now.UTC()
+		t = t.UTC()
 	}
 	if c.timeFormatter != nil {
-		return c.timeFormatter(now)
+		return c.timeFormatter(t)
 	}
-	return now.Format(c.timeLayout)
+	return t.Format(c.timeLayout)
 }
 
 type loggerBase struct {
 	cfg    coreConfig
 	fields []field
+	// dynamic holds the Lazy and Valuer pairs bound via With. They are kept
+	// out of fields so the static payload can still be pre-encoded.
+	dynamic []dynamicField
+	// groups lists the names opened via WithGroup, outermost first.
+	groups []string
+	// nameIndex is the 1-based position of the logger name field in fields,
+	// or 0 when the logger is unnamed.
+	nameIndex int
 }
 
 func newLoggerBase(cfg coreConfig, fields []field) loggerBase {
@@ -175,12 +267,35 @@ func newLoggerBase(cfg coreConfig, fields []field) loggerBase {
 
 func (b loggerBase) clone() loggerBase {
 	return loggerBase{
-		cfg:    b.cfg.clone(),
-		fields: cloneFields(b.fields),
+		cfg:       b.cfg.clone(),
+		fields:    cloneFields(b.fields),
+		dynamic:   b.dynamic,
+		groups:    b.groups,
+		nameIndex: b.nameIndex,
+	}
+}
+
+// payloadFields returns the static fields as they are encoded into the base
+// payload, with the redaction policy and error mode applied.
+func (b *loggerBase) payloadFields() []field {
+	fields := b.fields
+	if b.cfg.redactor != nil {
+		fields = b.cfg.redactor.fields(fields)
+	}
+	if b.cfg.errors != nil {
+		fields = b.cfg.errors.fields(fields)
 	}
+	return fields
 }
 
 func (b *loggerBase) withFields(additional []field) {
+	for i := range additional {
+		additional[i].group = len(b.groups)
+	}
+	additional, dynamic := splitDynamicFields(additional)
+	if len(dynamic) > 0 {
+		b.dynamic = append(slices.Clip(b.dynamic), dynamic...)
+	}
 	if len(additional) == 0 {
 		return
 	}
@@ -194,6 +309,39 @@ func (b *loggerBase) withFields(additional []field) {
 	b.fields = fields
 }
 
+// withName appends name to the logger's dotted name, updates the `logger`
+// field in place and applies the most specific matching namedLevels entry.
+func (b *loggerBase) withName(name string) {
+	if b.cfg.name != "" {
+		name = b.cfg.name + "." + name
+	}
+	b.cfg.name = name
+	f := field{key: loggerNameKey, value: name, trustedKey: true}
+	if b.nameIndex > 0 {
+		b.fields[b.nameIndex-1] = f
+	} else {
+		// The name stays at the top level, ahead of any grouped fields.
+		idx := len(b.fields)
+		for i, existing := range b.fields {
+			if existing.group > 0 {
+				idx = i
+				break
+			}
+		}
+		b.fields = slices.Insert(b.fields, idx, f)
+		b.nameIndex = idx + 1
+	}
+	if level, ok := b.cfg.namedLevel(name); ok {
+		if b.cfg.levelVar != nil && b.cfg.forcedLevel == nil {
+			// Stay attached to the shared LevelVar; see Options.NamedLevels.
+			b.cfg.nameLevel = &level
+			b.cfg.logLevelValue = LevelString(b.cfg.currentLevel())
+			return
+		}
+		b.withMinLevel(level)
+	}
+}
+
 func (b *loggerBase) withLogLevelField() {
 	if b.cfg.includeLogLevel {
 		return
@@ -211,6 +359,8 @@ func (b *loggerBase) withMinLevel(level Level) {
 	}
 	b.cfg.minLevel = level
 	b.cfg.forcedLevel = nil
+	b.cfg.levelVar = nil
+	b.cfg.nameLevel = nil
 	b.cfg.logLevelValue = LevelString(b.cfg.currentLevel())
 }
 
@@ -220,17 +370,38 @@ func (b *loggerBase) withForcedLevel(level Level) {
 	b.cfg.logLevelValue = LevelString(b.cfg.currentLevel())
 }
 
-func (b loggerBase) maybeAddCaller(keyvals []any) []any {
-	if !b.cfg.includeCaller {
+// maybeAddCaller appends the caller pair when CallerKeyval is enabled. Records
+// bridged from log/slog use the caller of their program counter. The
+// pair is appended to a copy held by lw, so the caller's slice is never
+// written to and no allocation is needed once the line writer has warmed up.
+// Grouped loggers keep the caller at the top level, so for them the pair is
+// stored in lw.caller instead and keyvals are returned unchanged.
+func (b loggerBase) maybeAddCaller(lw *lineWriter, keyvals []any) []any {
+	if !b.cfg.includeCaller || b.cfg.callerKey == "" {
 		return keyvals
 	}
-	key := b.cfg.callerKey
-	if key == "" {
-		return keyvals
+	var caller any
+	if lw.origin != nil && lw.origin.pc != 0 {
+		caller = callerValueForPC(lw.origin.pc, b.cfg.callerMode)
+	} else {
+		caller = callerValue(b.cfg.callerMode, b.cfg.callerSkip)
 	}
-	keyValue := any(key)
-	if b.cfg.callerKeyTrusted {
-		keyValue = TrustedString(key)
+	if len(b.groups) > 0 {
+		lw.caller = append(lw.caller[:0], b.cfg.callerKeyValue, caller)
+		return keyvals
 	}
-	return append(keyvals, keyValue, callerFunctionName())
+	lw.keyvals = append(append(lw.keyvals[:0], keyvals...), b.cfg.callerKeyValue, caller)
+	return lw.keyvals
+}
+
+// coreLogger is implemented by the concrete emitters. Internal adapters use it
+// to consult the resolved configuration and to derive siblings with an
+// adjusted coreConfig without going through the public option surface.
+type coreLogger interface {
+	Logger
+	core() *loggerBase
+	// logRecord logs like Log, but with the time and caller of a record
+	// bridged from log/slog.
+	logRecord(origin *recordOrigin, level Level, msg string, keyvals []any)
+	withCoreConfig(adjust func(*coreConfig)) Logger
 }
@@ -12,7 +12,7 @@ func TestCollectFieldsTracksTrustedKeys(t *testing.T) {
 		TrustedString("trusted"), 3,
 		123, 4,
 		"lonely",
-	})
+	}, false)
 
 	if len(fields) != 5 {
 		t.Fatalf("expected 5 fields, got %d", len(fields))
@@ -46,10 +46,10 @@ func TestCollectFieldsTracksTrustedKeys(t *testing.T) {
 }
 
 func TestCollectFieldsNilAndEmpty(t *testing.T) {
-	if fields := collectFields(nil); fields != nil {
+	if fields := collectFields(nil, false); fields != nil {
 		t.Fatalf("expected nil input to return nil slice")
 	}
-	if fields := collectFields([]any{}); fields != nil {
+	if fields := collectFields([]any{}, false); fields != nil {
 		t.Fatalf("expected empty input to return nil slice")
 	}
 }
@@ -57,7 +57,7 @@ func TestCollectFieldsNilAndEmpty(t *testing.T) {
 func TestCollectFieldsSingleError(t *testing.T) {
 	err := errors.New("boom")
 
-	fields := collectFields([]any{err})
+	fields := collectFields([]any{err}, false)
 	if len(fields) != 1 {
 		t.Fatalf("expected 1 field, got %d", len(fields))
 	}
@@ -77,3 +77,14 @@ func TestCollectFieldsSingleError(t *testing.T) {
 		t.Fatalf("expected error message %q, got %q", err.Error(), got)
 	}
 }
+
+func TestCollectFieldsKeepsErrorOnlyWhenRich(t *testing.T) {
+	err := errors.New("boom")
+
+	if value := collectFields([]any{err}, false)[0].value; errors.Is(value.(error), err) {
+		t.Fatalf("expected only the message to be kept, got %#v", value)
+	}
+	if value := collectFields([]any{err}, true)[0].value; !errors.Is(value.(error), err) {
+		t.Fatalf("expected the error to be kept for ErrorModeRich, got %#v", value)
+	}
+}
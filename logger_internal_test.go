@@ -25,7 +25,7 @@ func TestLoggerFatalAndPanicInternal(t *testing.T) {
 
 			called := false
 			origExit := exitProcess
-			exitProcess = func() { called = true }
+			exitProcess = func(int) { called = true }
 			t.Cleanup(func() { exitProcess = origExit })
 
 			logger.Fatal("fatal", "err", errors.New("fatal"))
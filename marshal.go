@@ -0,0 +1,456 @@
+package pslog
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"pkt.systems/pslog/ansi"
+)
+
+// ObjectMarshaler is implemented by types that log themselves as a nested
+// object. Structured loggers render the fields as a JSON object; console
+// loggers flatten them into dotted `key.sub=value` pairs. No reflection is
+// involved and the encoder writes straight into the line buffer.
+//
+// ObjectMarshaler takes precedence over fmt.Stringer, error and
+// json.Marshaler.
+type ObjectMarshaler interface {
+	MarshalLogObject(enc *ObjectEncoder)
+}
+
+// ArrayMarshaler is implemented by types that log themselves as an array.
+// Structured loggers render a JSON array; console loggers flatten the elements
+// into `key.0=value key.1=value` pairs.
+type ArrayMarshaler interface {
+	MarshalLogArray(enc *ArrayEncoder)
+}
+
+// ObjectMarshalerFunc adapts a function to ObjectMarshaler.
+type ObjectMarshalerFunc func(enc *ObjectEncoder)
+
+// MarshalLogObject calls f(enc).
+func (f ObjectMarshalerFunc) MarshalLogObject(enc *ObjectEncoder) { f(enc) }
+
+// ArrayMarshalerFunc adapts a function to ArrayMarshaler.
+type ArrayMarshalerFunc func(enc *ArrayEncoder)
+
+// MarshalLogArray calls f(enc).
+func (f ArrayMarshalerFunc) MarshalLogArray(enc *ArrayEncoder) { f(enc) }
+
+// ObjectEncoder adds fields to the object being logged. It is only valid for
+// the duration of the MarshalLogObject call.
+type ObjectEncoder struct {
+	lw      *lineWriter
+	palette *ansi.Palette
+	console bool
+	// first reports whether the next JSON field opens its object or array.
+	first   bool
+	inArray bool
+	index   int
+	// prefix is the dotted console path of the enclosing objects, including
+	// the trailing dot.
+	prefix []byte
+}
+
+// ArrayEncoder appends elements to the array being logged. It is only valid
+// for the duration of the MarshalLogArray call.
+type ArrayEncoder ObjectEncoder
+
+// String adds a string field.
+func (e *ObjectEncoder) String(key, value string) {
+	e.field(key, -1)
+	e.text(value, e.stringColor())
+}
+
+// Int adds an int field.
+func (e *ObjectEncoder) Int(key string, value int) { e.Int64(key, int64(value)) }
+
+// Int64 adds an int64 field.
+func (e *ObjectEncoder) Int64(key string, value int64) {
+	e.field(key, -1)
+	e.int(value)
+}
+
+// Uint64 adds a uint64 field.
+func (e *ObjectEncoder) Uint64(key string, value uint64) {
+	e.field(key, -1)
+	e.uint(value)
+}
+
+// Float64 adds a float64 field.
+func (e *ObjectEncoder) Float64(key string, value float64) {
+	e.field(key, -1)
+	e.float(value)
+}
+
+// Bool adds a bool field.
+func (e *ObjectEncoder) Bool(key string, value bool) {
+	e.field(key, -1)
+	e.bool(value)
+}
+
+// Time adds a time field rendered as RFC 3339.
+func (e *ObjectEncoder) Time(key string, value time.Time) {
+	e.field(key, -1)
+	e.time(value)
+}
+
+// Duration adds a duration field.
+func (e *ObjectEncoder) Duration(key string, value time.Duration) {
+	e.field(key, -1)
+	e.text(e.lw.formatDuration(value), e.stringColor())
+}
+
+// Object adds a nested object.
+func (e *ObjectEncoder) Object(key string, value ObjectMarshaler) {
+	e.object(key, -1, value)
+}
+
+// Array adds a nested array.
+func (e *ObjectEncoder) Array(key string, value ArrayMarshaler) {
+	e.array(key, -1, value)
+}
+
+// Any adds a field of any type using the same rules as runtime keyvals.
+func (e *ObjectEncoder) Any(key string, value any) {
+	e.any(key, -1, value)
+}
+
+// AppendString appends a string element.
+func (a *ArrayEncoder) AppendString(value string) {
+	e := a.elem()
+	e.text(value, e.stringColor())
+}
+
+// AppendInt appends an int element.
+func (a *ArrayEncoder) AppendInt(value int) { a.AppendInt64(int64(value)) }
+
+// AppendInt64 appends an int64 element.
+func (a *ArrayEncoder) AppendInt64(value int64) { a.elem().int(value) }
+
+// AppendUint64 appends a uint64 element.
+func (a *ArrayEncoder) AppendUint64(value uint64) { a.elem().uint(value) }
+
+// AppendFloat64 appends a float64 element.
+func (a *ArrayEncoder) AppendFloat64(value float64) { a.elem().float(value) }
+
+// AppendBool appends a bool element.
+func (a *ArrayEncoder) AppendBool(value bool) { a.elem().bool(value) }
+
+// AppendTime appends a time element rendered as RFC 3339.
+func (a *ArrayEncoder) AppendTime(value time.Time) { a.elem().time(value) }
+
+// AppendDuration appends a duration element.
+func (a *ArrayEncoder) AppendDuration(value time.Duration) {
+	e := a.elem()
+	e.text(e.lw.formatDuration(value), e.stringColor())
+}
+
+// AppendObject appends a nested object.
+func (a *ArrayEncoder) AppendObject(value ObjectMarshaler) {
+	e := (*ObjectEncoder)(a)
+	e.object("", a.next(), value)
+}
+
+// AppendArray appends a nested array.
+func (a *ArrayEncoder) AppendArray(value ArrayMarshaler) {
+	e := (*ObjectEncoder)(a)
+	e.array("", a.next(), value)
+}
+
+// AppendAny appends an element of any type.
+func (a *ArrayEncoder) AppendAny(value any) {
+	e := (*ObjectEncoder)(a)
+	e.any("", a.next(), value)
+}
+
+func (a *ArrayEncoder) next() int {
+	i := a.index
+	a.index++
+	return i
+}
+
+func (a *ArrayEncoder) elem() *ObjectEncoder {
+	e := (*ObjectEncoder)(a)
+	e.field("", a.next())
+	return e
+}
+
+// field writes the separator and key for the next value. Console keys are
+// the dotted prefix followed by key, or by index for array elements.
+func (e *ObjectEncoder) field(key string, index int) {
+	lw := e.lw
+	if !e.console {
+		if e.first {
+			e.first = false
+		} else {
+			lw.writeByte(',')
+		}
+		if e.inArray {
+			return
+		}
+		if e.palette != nil {
+			writeColoredKey(lw, key, e.palette.Key, stringTrustedASCII(key))
+			lw.writeByte(':')
+			return
+		}
+		writePTJSONStringWithColon(lw, key)
+		return
+	}
+	lw.writeByte(' ')
+	if e.palette != nil {
+		lw.writeString(e.palette.Key)
+	}
+	lw.writeBytes(e.prefix)
+	if index >= 0 {
+		lw.writeInt64(int64(index))
+	} else {
+		lw.writeString(key)
+	}
+	lw.writeByte('=')
+	if e.palette != nil {
+		lw.writeString(ansi.Reset)
+	}
+}
+
+func (e *ObjectEncoder) stringColor() string {
+	if e.palette == nil {
+		return ""
+	}
+	return e.palette.String
+}
+
+func (e *ObjectEncoder) text(s string, color string) {
+	switch {
+	case e.console && e.palette != nil:
+		writeConsoleStringColor(e.lw, s, color)
+	case e.console:
+		writeConsoleStringPlain(e.lw, s)
+	case e.palette != nil:
+		writePTJSONStringColored(e.lw, color, s)
+	default:
+		writePTJSONString(e.lw, s)
+	}
+}
+
+func (e *ObjectEncoder) int(n int64) {
+	switch {
+	case e.console && e.palette != nil:
+		writeConsoleIntColor(e.lw, n, e.palette)
+	case e.console:
+		writeConsoleIntPlain(e.lw, n)
+	case e.palette != nil:
+		writeJSONNumberColored(e.lw, n, e.palette.Num)
+	default:
+		writeJSONNumber(e.lw, n, false)
+	}
+}
+
+func (e *ObjectEncoder) uint(n uint64) {
+	switch {
+	case e.console && e.palette != nil:
+		writeConsoleUintColor(e.lw, n, e.palette)
+	case e.console:
+		writeConsoleUintPlain(e.lw, n)
+	case e.palette != nil:
+		writeJSONUintColored(e.lw, n, e.palette.Num)
+	default:
+		writeJSONUint(e.lw, n, false)
+	}
+}
+
+func (e *ObjectEncoder) float(f float64) {
+	switch {
+	case e.console && e.palette != nil:
+		writeConsoleFloatColor(e.lw, f, e.palette)
+	case e.console:
+		writeConsoleFloatPlain(e.lw, f)
+	case e.palette != nil:
+		writeJSONFloatColored(e.lw, f, e.palette.Num)
+	default:
+		writeJSONFloat(e.lw, f, false)
+	}
+}
+
+func (e *ObjectEncoder) bool(v bool) {
+	switch {
+	case e.console && e.palette != nil:
+		writeConsoleBoolColor(e.lw, v, e.palette)
+	case e.console:
+		writeConsoleBoolPlain(e.lw, v)
+	case e.palette != nil:
+		writeJSONBoolColored(e.lw, v, e.palette.Bool)
+	default:
+		e.lw.writeBool(v)
+	}
+}
+
+func (e *ObjectEncoder) time(t time.Time) {
+	color := ""
+	if e.palette != nil {
+		color = e.palette.Timestamp
+	}
+	e.text(e.lw.formatTimeRFC3339(t), color)
+}
+
+func (e *ObjectEncoder) any(key string, index int, value any) {
+	value = resolveValue(value)
+	switch v := value.(type) {
+	case ObjectMarshaler:
+		e.object(key, index, v)
+		return
+	case ArrayMarshaler:
+		e.array(key, index, v)
+		return
+	}
+	e.field(key, index)
+	lw := e.lw
+	switch {
+	case e.console && e.palette != nil:
+		writeConsoleValueColor(lw, value, e.palette)
+	case e.console:
+		if !writeConsoleValueInline(lw, value) {
+			writeConsoleValuePlain(lw, value)
+		}
+	case e.palette != nil:
+		writeRuntimeJSONValueColor(lw, value, e.palette)
+	default:
+		writePTLogValue(lw, value)
+	}
+}
+
+func (e *ObjectEncoder) object(key string, index int, m ObjectMarshaler) {
+	if !e.console {
+		e.field(key, index)
+		e.objectBody(m)
+		return
+	}
+	prefixLen, inArray, next := len(e.prefix), e.inArray, e.index
+	e.prefix = appendConsolePathSegment(e.prefix, key, index)
+	e.inArray = false
+	mark := len(e.lw.buf)
+	m.MarshalLogObject(e)
+	e.prefix, e.inArray, e.index = e.prefix[:prefixLen], inArray, next
+	if len(e.lw.buf) == mark {
+		e.field(key, index)
+		e.lw.writeString("{}")
+	}
+}
+
+func (e *ObjectEncoder) array(key string, index int, m ArrayMarshaler) {
+	if !e.console {
+		e.field(key, index)
+		e.arrayBody(m)
+		return
+	}
+	prefixLen, inArray, next := len(e.prefix), e.inArray, e.index
+	e.prefix = appendConsolePathSegment(e.prefix, key, index)
+	e.inArray, e.index = true, 0
+	mark := len(e.lw.buf)
+	m.MarshalLogArray((*ArrayEncoder)(e))
+	e.prefix, e.inArray, e.index = e.prefix[:prefixLen], inArray, next
+	if len(e.lw.buf) == mark {
+		e.field(key, index)
+		e.lw.writeString("[]")
+	}
+}
+
+func (e *ObjectEncoder) objectBody(m ObjectMarshaler) {
+	first, inArray, next := e.first, e.inArray, e.index
+	e.first, e.inArray = true, false
+	e.lw.writeByte('{')
+	m.MarshalLogObject(e)
+	e.lw.writeByte('}')
+	e.first, e.inArray, e.index = first, inArray, next
+}
+
+func (e *ObjectEncoder) arrayBody(m ArrayMarshaler) {
+	first, inArray, next := e.first, e.inArray, e.index
+	e.first, e.inArray, e.index = true, true, 0
+	e.lw.writeByte('[')
+	m.MarshalLogArray((*ArrayEncoder)(e))
+	e.lw.writeByte(']')
+	e.first, e.inArray, e.index = first, inArray, next
+}
+
+func appendConsolePathSegment(prefix []byte, key string, index int) []byte {
+	if index >= 0 {
+		prefix = strconv.AppendInt(prefix, int64(index), 10)
+	} else {
+		prefix = append(prefix, key...)
+	}
+	return append(prefix, '.')
+}
+
+// objectEncoder returns the line writer's encoder, or a fresh one when a
+// marshaler logs another marshaler through a generic value path.
+func (lw *lineWriter) objectEncoder(console bool, palette *ansi.Palette) *ObjectEncoder {
+	e := &lw.enc
+	if e.lw != nil {
+		e = &ObjectEncoder{}
+	}
+	e.lw, e.console, e.palette = lw, console, palette
+	e.first, e.inArray, e.index = true, false, 0
+	e.prefix = e.prefix[:0]
+	return e
+}
+
+func (e *ObjectEncoder) release() {
+	e.lw = nil
+	e.palette = nil
+}
+
+func isLogMarshaler(value any) bool {
+	switch value.(type) {
+	case ObjectMarshaler, ArrayMarshaler:
+		return true
+	}
+	return false
+}
+
+// writeJSONMarshaler writes an ObjectMarshaler or ArrayMarshaler as a JSON
+// value. A nil palette renders plain JSON.
+func writeJSONMarshaler(lw *lineWriter, value any, palette *ansi.Palette) {
+	e := lw.objectEncoder(false, palette)
+	switch m := value.(type) {
+	case ObjectMarshaler:
+		e.objectBody(m)
+	case ArrayMarshaler:
+		e.arrayBody(m)
+	}
+	e.release()
+}
+
+// writeConsoleMarshaler writes value as flattened ` key.sub=value` pairs.
+func writeConsoleMarshaler(lw *lineWriter, key string, value any, palette *ansi.Palette) {
+	e := lw.objectEncoder(true, palette)
+	e.any(key, -1, value)
+	e.release()
+}
+
+// appendConsoleMarshaler is the static-field counterpart of
+// writeConsoleMarshaler. It reports false when value is not a marshaler.
+func appendConsoleMarshaler(buf []byte, key string, value any, palette *ansi.Palette) ([]byte, bool) {
+	if !isLogMarshaler(value) {
+		return buf, false
+	}
+	lw := acquireLineWriter(io.Discard)
+	lw.autoFlush = false
+	writeConsoleMarshaler(lw, key, value, palette)
+	buf = append(buf, lw.buf...)
+	releaseLineWriter(lw)
+	return buf, true
+}
+
+// writeConsoleMarshalerPair flattens ObjectMarshaler and ArrayMarshaler
+// values into ` key.sub=value` pairs. The pair loops call it before writing
+// ` key=`, so nothing has to be taken back; it reports false, having written
+// nothing, for every other value. A nil palette renders plain text.
+func writeConsoleMarshalerPair(lw *lineWriter, key string, value any, palette *ansi.Palette) bool {
+	if !isLogMarshaler(value) {
+		return false
+	}
+	writeConsoleMarshaler(lw, key, value, palette)
+	return true
+}
@@ -0,0 +1,165 @@
+package pslog_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"pkt.systems/pslog"
+	"pkt.systems/pslog/ansi"
+	"pkt.systems/pslog/internal/race"
+)
+
+type testAddress struct {
+	City string
+	Zip  int
+}
+
+func (a *testAddress) MarshalLogObject(enc *pslog.ObjectEncoder) {
+	enc.String("city", a.City)
+	enc.Int("zip", a.Zip)
+}
+
+type testRoles []string
+
+func (r *testRoles) MarshalLogArray(enc *pslog.ArrayEncoder) {
+	for _, role := range *r {
+		enc.AppendString(role)
+	}
+}
+
+type testUser struct {
+	Name    string
+	ID      int64
+	Active  bool
+	Roles   testRoles
+	Address *testAddress
+	Timeout time.Duration
+}
+
+func (u *testUser) MarshalLogObject(enc *pslog.ObjectEncoder) {
+	enc.String("name", u.Name)
+	enc.Int64("id", u.ID)
+	enc.Bool("active", u.Active)
+	enc.Array("roles", &u.Roles)
+	enc.Object("address", u.Address)
+	enc.Duration("timeout", u.Timeout)
+}
+
+// String must lose to MarshalLogObject.
+func (u *testUser) String() string { return "user:" + u.Name }
+
+func newTestUser() *testUser {
+	return &testUser{
+		Name:    "Ada Lovelace",
+		ID:      7,
+		Active:  true,
+		Roles:   testRoles{"admin", "dev"},
+		Address: &testAddress{City: "London", Zip: 1815},
+		Timeout: 1500 * time.Millisecond,
+	}
+}
+
+func TestObjectMarshalerAcrossVariants(t *testing.T) {
+	jsonLine := `{"lvl":"info","msg":"login","owner":{"name":"Ada Lovelace","id":7,"active":true,"roles":["admin","dev"],"address":{"city":"London","zip":1815},"timeout":"1.5s"},"user":{"name":"Ada Lovelace","id":7,"active":true,"roles":["admin","dev"],"address":{"city":"London","zip":1815},"timeout":"1.5s"},"tags":[],"ok":true}`
+	consoleFields := `owner.name="Ada Lovelace" owner.id=7 owner.active=true owner.roles.0=admin owner.roles.1=dev owner.address.city=London owner.address.zip=1815 owner.timeout=1.5s`
+	consoleLine := `INF login ` + consoleFields + ` ` + strings.ReplaceAll(consoleFields, "owner.", "user.") + ` tags=[] ok=true`
+
+	variants := []struct {
+		name string
+		opts pslog.Options
+		want string
+	}{
+		{"json_plain", pslog.Options{Mode: pslog.ModeStructured, NoColor: true}, jsonLine},
+		{"json_color", pslog.Options{Mode: pslog.ModeStructured, ForceColor: true}, jsonLine},
+		{"console_plain", pslog.Options{Mode: pslog.ModeConsole, NoColor: true}, consoleLine},
+		{"console_color", pslog.Options{Mode: pslog.ModeConsole, ForceColor: true}, consoleLine},
+	}
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := variant.opts
+			opts.DisableTimestamp = true
+			user := newTestUser()
+			logger := pslog.NewWithOptions(nil, &buf, opts).With("owner", user)
+			logger.Info("login", "user", user, "tags", &testRoles{}, "ok", true)
+
+			if got := stripANSI(strings.TrimSpace(buf.String())); got != variant.want {
+				t.Fatalf("unexpected output:\n got %s\nwant %s", got, variant.want)
+			}
+		})
+	}
+}
+
+func TestObjectMarshalerNestingAndAny(t *testing.T) {
+	obj := pslog.ObjectMarshalerFunc(func(enc *pslog.ObjectEncoder) {
+		enc.Any("n", 1.5)
+		enc.Any("addr", &testAddress{City: "Oslo", Zip: 150})
+		enc.Array("matrix", pslog.ArrayMarshalerFunc(func(enc *pslog.ArrayEncoder) {
+			enc.AppendArray(&testRoles{"a"})
+			enc.AppendObject(pslog.ObjectMarshalerFunc(func(enc *pslog.ObjectEncoder) {}))
+			enc.AppendInt(3)
+		}))
+	})
+
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true})
+	logger.Info("msg", "obj", obj)
+	if got, want := strings.TrimSpace(buf.String()), `{"lvl":"info","msg":"msg","obj":{"n":1.5,"addr":{"city":"Oslo","zip":150},"matrix":[["a"],{},3]}}`; got != want {
+		t.Fatalf("unexpected JSON:\n got %s\nwant %s", got, want)
+	}
+
+	buf.Reset()
+	logger = pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, NoColor: true})
+	logger.Info("msg", "obj", obj)
+	if got, want := strings.TrimSpace(buf.String()), `INF msg obj.n=1.5 obj.addr.city=Oslo obj.addr.zip=150 obj.matrix.0.0=a obj.matrix.1={} obj.matrix.2=3`; got != want {
+		t.Fatalf("unexpected console:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestObjectMarshalerAllocateZero(t *testing.T) {
+	if race.Enabled {
+		t.Skip("sync.Pool drops pooled line writers under -race")
+	}
+	user := newTestUser()
+	keyvals := []any{"user", user}
+	cases := []struct {
+		name string
+		opts pslog.Options
+	}{
+		{"console_plain", pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, NoColor: true}},
+		{"console_color", pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, ForceColor: true}},
+		{"json_plain", pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true}},
+		{"json_color", pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, ForceColor: true}},
+	}
+	for _, tc := range cases {
+		logger := pslog.NewWithOptions(nil, io.Discard, tc.opts)
+		logger.Info("warm", keyvals...)
+		allocs := testing.AllocsPerRun(1000, func() {
+			logger.Info("msg", keyvals...)
+		})
+		if allocs != 0 {
+			t.Fatalf("%s: expected 0 allocs/log, got %.2f", tc.name, allocs)
+		}
+	}
+}
+
+func TestConsoleMarshalerKeysOnFastAndSlowPaths(t *testing.T) {
+	addr := &testAddress{City: "Oslo", Zip: 150}
+	want := "INF msg a=1 addr.city=Oslo addr.zip=150\nINF msg 7.city=Oslo 7.zip=150 b=2"
+	for _, opts := range []pslog.Options{
+		{Mode: pslog.ModeConsole, NoColor: true},
+		{Mode: pslog.ModeConsole, ForceColor: true, Palette: &ansi.PaletteTokyoNight},
+	} {
+		var buf bytes.Buffer
+		opts.DisableTimestamp = true
+		logger := pslog.NewWithOptions(nil, &buf, opts)
+		logger.Info("msg", "a", 1, "addr", pslog.Valuer(func() any { return addr }))
+		logger.Info("msg", 7, addr, "b", 2)
+		if got := stripANSI(strings.TrimSpace(buf.String())); got != want {
+			t.Fatalf("unexpected output:\n got %s\nwant %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package pslog
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Sink is one destination of a logger built by NewMulti.
+type Sink struct {
+	// Writer receives the sink's lines. Nil discards them.
+	Writer io.Writer
+
+	// Options configures the sink exactly like NewWithOptions: Mode,
+	// MinLevel, Palette and the colour decision (NoColor, ForceColor or
+	// terminal detection on Writer) are resolved per sink. Hooks, Sampling,
+	// Redact and ErrorMode are per sink as well: each sink runs its own hook
+	// chain and keeps its own sampling budget, so hooks run once per sink
+	// that accepts the entry. Only the first sink's Fatal options are used.
+	Options Options
+}
+
+// multiLogger fans every call out to one emitter per sink. Each emitter keeps
+// its own level and pre-encoded static payload, so With encodes the fields
+// once per sink rather than once per entry.
+type multiLogger struct {
+	loggers []Logger
+	fatal   *fatalConfig
+}
+
+// NewMulti returns a logger that writes every entry to each of sinks, e.g.
+// colourful console lines on a terminal and plain JSON in a file. With, Named,
+// WithGroup and the level methods apply to every sink.
+//
+// Lazy values, and Valuer values passed to a log call, are evaluated at most
+// once per entry and the result is shared by every sink. A Valuer bound via
+// With is evaluated once per entry by each sink that writes it.
+//
+// Fatal writes the entry to all sinks, then follows the first sink's
+// Options.Fatal (exit code, shutdown hooks and Exit); the Fatal options of the
+// other sinks are ignored. Every sink is closed before exiting. ctx controls
+// runtime lifecycle; cancellation tears down logger-owned resources.
+func NewMulti(ctx context.Context, sinks ...Sink) Logger {
+	switch len(sinks) {
+	case 0:
+		return noopLogger{}
+	case 1:
+		return buildAdapter(ctx, sinks[0].Writer, sinks[0].Options)
+	}
+	m := &multiLogger{
+		loggers: make([]Logger, len(sinks)),
+		fatal:   newFatalConfig(sinks[0].Options.Fatal),
+	}
+	for i, sink := range sinks {
+		m.loggers[i] = buildAdapter(ctx, sink.Writer, sink.Options)
+	}
+	return m
+}
+
+func (m *multiLogger) Trace(msg string, keyvals ...any) { m.log(TraceLevel, msg, keyvals) }
+func (m *multiLogger) Debug(msg string, keyvals ...any) { m.log(DebugLevel, msg, keyvals) }
+func (m *multiLogger) Info(msg string, keyvals ...any)  { m.log(InfoLevel, msg, keyvals) }
+func (m *multiLogger) Warn(msg string, keyvals ...any)  { m.log(WarnLevel, msg, keyvals) }
+func (m *multiLogger) Error(msg string, keyvals ...any) { m.log(ErrorLevel, msg, keyvals) }
+
+func (m *multiLogger) Fatal(msg string, keyvals ...any) {
+	m.log(FatalLevel, msg, keyvals)
+	m.fatal.run(func() {
+		_ = m.Close()
+	})
+}
+
+func (m *multiLogger) Panic(msg string, keyvals ...any) {
+	m.log(PanicLevel, msg, keyvals)
+	panic(msg)
+}
+
+func (m *multiLogger) Log(level Level, msg string, keyvals ...any) {
+	m.log(level, msg, keyvals)
+}
+
+func (m *multiLogger) log(level Level, msg string, keyvals []any) {
+	keyvals = shareDeferred(keyvals)
+	for _, logger := range m.loggers {
+		logger.Log(level, msg, keyvals...)
+	}
+}
+
+// shareDeferred returns keyvals with every Lazy and Valuer wrapped in a
+// memoising value, so the sinks that write the entry share one evaluation and
+// sinks that drop it never trigger one. keyvals is returned as is when it
+// holds no such values.
+func shareDeferred(keyvals []any) []any {
+	var out []any
+	for i, v := range keyvals {
+		var fn Lazy
+		switch d := v.(type) {
+		case Lazy:
+			fn = d
+		case Valuer:
+			fn = Lazy(d)
+		default:
+			continue
+		}
+		if out == nil {
+			out = append([]any(nil), keyvals...)
+		}
+		out[i] = &onceValue{fn: fn}
+	}
+	if out == nil {
+		return keyvals
+	}
+	return out
+}
+
+// each returns a multiLogger whose sinks are derived from the receiver's.
+func (m *multiLogger) each(derive func(Logger) Logger) Logger {
+	clone := &multiLogger{loggers: make([]Logger, len(m.loggers)), fatal: m.fatal}
+	for i, logger := range m.loggers {
+		clone.loggers[i] = derive(logger)
+	}
+	return clone
+}
+
+func (m *multiLogger) With(keyvals ...any) Logger {
+	if len(keyvals) == 0 {
+		return m
+	}
+	keyvals = shareLazy(keyvals)
+	return m.each(func(l Logger) Logger { return l.With(keyvals...) })
+}
+
+// shareLazy wraps the Lazy values among With keyvals once, so every sink
+// reuses the same memoised result. Valuers are left alone: bound via With,
+// they are evaluated for every entry by each sink.
+func shareLazy(keyvals []any) []any {
+	var out []any
+	for i, v := range keyvals {
+		fn, ok := v.(Lazy)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]any(nil), keyvals...)
+		}
+		out[i] = &onceValue{fn: fn}
+	}
+	if out == nil {
+		return keyvals
+	}
+	return out
+}
+
+func (m *multiLogger) WithLogLevel() Logger {
+	return m.each(func(l Logger) Logger { return l.WithLogLevel() })
+}
+
+func (m *multiLogger) LogLevel(level Level) Logger {
+	return m.each(func(l Logger) Logger { return l.LogLevel(level) })
+}
+
+func (m *multiLogger) LogLevelFromEnv(key string) Logger {
+	if level, ok := LevelFromEnv(key); ok {
+		return m.LogLevel(level)
+	}
+	return m
+}
+
+func (m *multiLogger) Named(name string) Logger {
+	if name == "" {
+		return m
+	}
+	return m.each(func(l Logger) Logger { return Named(l, name) })
+}
+
+func (m *multiLogger) WithGroup(name string) Logger {
+	if name == "" {
+		return m
+	}
+	return m.each(func(l Logger) Logger { return WithGroup(l, name) })
+}
+
+// Close closes every sink; see the package-level Close.
+func (m *multiLogger) Close() error {
+	var errs []error
+	for _, logger := range m.loggers {
+		if err := Close(logger); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush flushes every sink; see the package-level Flush.
+func (m *multiLogger) Flush(ctx context.Context) error {
+	var errs []error
+	for _, logger := range m.loggers {
+		if err := Flush(ctx, logger); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
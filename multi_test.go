@@ -0,0 +1,119 @@
+package pslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+	"pkt.systems/pslog/ansi"
+)
+
+func TestNewMultiRendersEachSinkWithItsOwnOptions(t *testing.T) {
+	var console, jsonBuf bytes.Buffer
+	logger := pslog.NewMulti(context.Background(),
+		pslog.Sink{Writer: &console, Options: pslog.Options{Mode: pslog.ModeConsole, ForceColor: true, Palette: &ansi.PaletteDefault, DisableTimestamp: true, MinLevel: pslog.WarnLevel}},
+		pslog.Sink{Writer: &jsonBuf, Options: pslog.Options{Mode: pslog.ModeStructured, NoColor: true, DisableTimestamp: true}},
+	)
+	svc := pslog.Named(logger.With("svc", "api"), "http")
+	svc.Info("started", "port", 8080)
+	svc.Warn("slow", "ms", 1200)
+
+	if strings.Contains(console.String(), "started") {
+		t.Fatalf("console sink should filter below warn, got %q", console.String())
+	}
+	if !strings.Contains(console.String(), "\x1b[") || !strings.Contains(stripANSI(console.String()), "svc=api") {
+		t.Fatalf("expected coloured console line with static fields, got %q", console.String())
+	}
+	lines := strings.Split(strings.TrimSpace(jsonBuf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON lines, got %q", jsonBuf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[0], err)
+	}
+	if entry["svc"] != "api" || entry["logger"] != "http" || entry["port"] != float64(8080) {
+		t.Fatalf("unexpected JSON entry %v", entry)
+	}
+	if strings.Contains(jsonBuf.String(), "\x1b[") {
+		t.Fatalf("JSON sink must not be coloured: %q", jsonBuf.String())
+	}
+}
+
+func TestNewMultiLevelAndGroupApplyToAllSinks(t *testing.T) {
+	var a, b bytes.Buffer
+	logger := pslog.WithGroup(pslog.NewMulti(context.Background(),
+		pslog.Sink{Writer: &a, Options: pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true}},
+		pslog.Sink{Writer: &b, Options: pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true}},
+	).LogLevel(pslog.ErrorLevel), "req")
+
+	logger.Warn("dropped")
+	logger.Error("kept", "id", 1)
+	for _, buf := range []*bytes.Buffer{&a, &b} {
+		if got := strings.TrimSpace(buf.String()); got != `{"lvl":"error","msg":"kept","req":{"id":1}}` {
+			t.Fatalf("unexpected sink output %q", got)
+		}
+	}
+}
+
+func TestNewMultiFatalClosesSinksAndUsesFirstSinkOptions(t *testing.T) {
+	var a, b bytes.Buffer
+	asyncA := pslog.NewAsyncWriter(&a, pslog.AsyncOptions{})
+	asyncB := pslog.NewAsyncWriter(&b, pslog.AsyncOptions{})
+	code := -1
+	logger := pslog.NewMulti(context.Background(),
+		pslog.Sink{Writer: asyncA, Options: pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, Fatal: pslog.FatalOptions{ExitCode: 7, Exit: func(c int) { code = c }}}},
+		pslog.Sink{Writer: asyncB, Options: pslog.Options{Mode: pslog.ModeConsole, NoColor: true, DisableTimestamp: true}},
+	)
+	logger.Fatal("bye")
+
+	if code != 7 {
+		t.Fatalf("expected exit code 7, got %d", code)
+	}
+	if !strings.Contains(a.String(), `"msg":"bye"`) || !strings.Contains(b.String(), "bye") {
+		t.Fatalf("expected both sinks drained before exit, got %q and %q", a.String(), b.String())
+	}
+	if _, err := asyncB.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("expected second sink to be closed, got %v", err)
+	}
+}
+
+func TestNewMultiSingleSinkAndEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewMulti(context.Background(), pslog.Sink{Writer: &buf, Options: pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true}})
+	logger.Info("one")
+	if got := strings.TrimSpace(buf.String()); got != `{"lvl":"info","msg":"one"}` {
+		t.Fatalf("unexpected output %q", got)
+	}
+	pslog.NewMulti(context.Background()).Info("discarded")
+}
+
+func TestNewMultiEvaluatesDeferredValuesOncePerEntry(t *testing.T) {
+	var a, b bytes.Buffer
+	opts := pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true, MinLevel: pslog.InfoLevel}
+	warnOnly := opts
+	warnOnly.MinLevel = pslog.WarnLevel
+	logger := pslog.NewMulti(context.Background(),
+		pslog.Sink{Writer: &a, Options: opts},
+		pslog.Sink{Writer: &b, Options: opts},
+		pslog.Sink{Writer: &bytes.Buffer{}, Options: warnOnly},
+	)
+	loads, runtimeCalls, skipped := 0, 0, 0
+	child := logger.With("cfg", pslog.Lazy(func() any { loads++; return "loaded" }))
+	child.Info("tick", "seq", pslog.Valuer(func() any { runtimeCalls++; return runtimeCalls }))
+	child.Info("tick", "seq", pslog.Valuer(func() any { runtimeCalls++; return runtimeCalls }))
+	child.Debug("hidden", "dump", pslog.Lazy(func() any { skipped++; return "x" }))
+
+	want := "{\"lvl\":\"info\",\"msg\":\"tick\",\"cfg\":\"loaded\",\"seq\":1}\n{\"lvl\":\"info\",\"msg\":\"tick\",\"cfg\":\"loaded\",\"seq\":2}\n"
+	if a.String() != want || b.String() != want {
+		t.Fatalf("sinks disagree:\n%s\n%s", a.String(), b.String())
+	}
+	if loads != 1 || runtimeCalls != 2 || skipped != 0 {
+		t.Fatalf("expected one evaluation per entry, got loads=%d runtime=%d skipped=%d", loads, runtimeCalls, skipped)
+	}
+}
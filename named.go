@@ -0,0 +1,22 @@
+package pslog
+
+// NamedLogger is implemented by loggers that support hierarchical names.
+// Every logger pslog constructs implements it. Named is kept off Logger so
+// existing Logger implementations, mocks and wrappers stay valid; use the
+// package-level Named to name any Logger.
+type NamedLogger interface {
+	Logger
+	// Named returns a logger whose name is the receiver's name extended with
+	// name, joined by a dot. The full name is rendered as a `logger` field and
+	// selects the matching Options.NamedLevels entry, if any.
+	Named(name string) Logger
+}
+
+// Named returns logger extended with name when it implements NamedLogger and
+// logger unchanged otherwise.
+func Named(logger Logger, name string) Logger {
+	if named, ok := logger.(NamedLogger); ok {
+		return named.Named(name)
+	}
+	return logger
+}
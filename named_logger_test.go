@@ -0,0 +1,122 @@
+package pslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+func TestNamedLoggerJoinsNames(t *testing.T) {
+	variants := []struct {
+		name string
+		opts pslog.Options
+		want string
+	}{
+		{"json_plain", pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true}, `{"lvl":"info","msg":"ready","svc":"api","logger":"db.pool","n":1}`},
+		{"json_color", pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, ForceColor: true}, `{"lvl":"info","msg":"ready","svc":"api","logger":"db.pool","n":1}`},
+		{"console_plain", pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, NoColor: true}, `INF ready svc=api logger=db.pool n=1`},
+		{"console_color", pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, ForceColor: true}, `INF ready svc=api logger=db.pool n=1`},
+	}
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := pslog.Named(pslog.Named(pslog.Named(pslog.NewWithOptions(nil, &buf, variant.opts).With("svc", "api"), "db"), ""), "pool")
+			logger.Info("ready", "n", 1)
+			if got := stripANSI(strings.TrimSpace(buf.String())); got != variant.want {
+				t.Fatalf("unexpected output:\n got %s\nwant %s", got, variant.want)
+			}
+		})
+	}
+}
+
+func TestNamedLoggerLevelOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	root := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		MinLevel:         pslog.InfoLevel,
+		NamedLevels:      map[string]pslog.Level{"db": pslog.DebugLevel, "db.pool": pslog.TraceLevel},
+	})
+
+	root.Debug("root_debug")
+	db := pslog.Named(root, "db")
+	db.Debug("db_debug")
+	db.Trace("db_trace")
+	pslog.Named(db, "pool").Trace("pool_trace")
+	pslog.Named(db, "tx").Debug("tx_debug")
+	pslog.Named(db, "tx").Trace("tx_trace")
+	pslog.Named(root, "http").Debug("http_debug")
+
+	out := buf.String()
+	for _, want := range []string{"db_debug", "pool_trace", "tx_debug"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %s in output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"root_debug", "db_trace", "tx_trace", "http_debug"} {
+		if strings.Contains(out, unwanted) {
+			t.Fatalf("unexpected %s in output:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestNamedLoggerLevelsFromEnv(t *testing.T) {
+	t.Setenv("PSLOG_TEST_LEVEL", "warn,db=debug,db.pool=trace")
+
+	var buf bytes.Buffer
+	logger := pslog.LoggerFromEnv(nil,
+		pslog.WithEnvPrefix("PSLOG_TEST_"),
+		pslog.WithEnvWriter(&buf),
+		pslog.WithEnvOptions(pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true}),
+	)
+	logger.Info("root_info")
+	pslog.Named(logger, "db.pool").Trace("pool_trace")
+
+	lines := collectLines(&buf)
+	if len(lines) != 1 {
+		t.Fatalf("expected only the pool trace line, got %v", lines)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &payload); err != nil {
+		t.Fatalf("invalid json %q: %v", lines[0], err)
+	}
+	if payload["msg"] != "pool_trace" || payload["logger"] != "db.pool" {
+		t.Fatalf("unexpected payload: %v", payload)
+	}
+}
+
+func TestNamedLoggerLevelOverrideFollowsLevelVar(t *testing.T) {
+	var buf bytes.Buffer
+	level := pslog.NewLevelVar(pslog.InfoLevel)
+	root := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		LevelVar:         level,
+		NamedLevels:      map[string]pslog.Level{"db": pslog.WarnLevel},
+	})
+	db := pslog.Named(root, "db")
+
+	db.Info("db_info_quiet")
+	level.Set(pslog.DebugLevel)
+	db.Debug("db_debug_shared")
+	level.Set(pslog.InfoLevel)
+	db.Info("db_info_restored")
+	db.Warn("db_warn")
+
+	out := buf.String()
+	for _, want := range []string{"db_debug_shared", "db_warn"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %s in output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"db_info_quiet", "db_info_restored"} {
+		if strings.Contains(out, unwanted) {
+			t.Fatalf("unexpected %s in output:\n%s", unwanted, out)
+		}
+	}
+}
@@ -20,3 +20,5 @@ func (n noopLogger) With(...any) Logger            { return n }
 func (n noopLogger) WithLogLevel() Logger          { return n }
 func (n noopLogger) LogLevel(Level) Logger         { return n }
 func (n noopLogger) LogLevelFromEnv(string) Logger { return n }
+func (n noopLogger) Named(string) Logger           { return n }
+func (n noopLogger) WithGroup(string) Logger       { return n }
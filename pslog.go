@@ -5,6 +5,7 @@ import (
 	"context"
 	"io"
 	"log"
+	"maps"
 	"os"
 	"strings"
 	"time"
@@ -191,6 +192,53 @@ type Options struct {
 	// MinLevel sets the minimum level the adapter will emit. Defaults to Debug.
 	MinLevel Level
 
+	// LevelVar, when set, replaces MinLevel with a level that can be changed
+	// at runtime. Every logger derived from the adapter follows the shared
+	// value until LogLevel pins it to a fixed level.
+	LevelVar *LevelVar
+
+	// NamedLevels overrides the minimum level of named loggers (see Named).
+	// Keys are dotted logger names and the most specific entry wins, so "db"
+	// also applies to "db.pool" unless "db.pool" is present. With LevelVar
+	// set, an override applies while the LevelVar holds the level it had when
+	// the adapter was built; once the LevelVar is changed, named loggers
+	// follow it like every other logger until it is set back.
+	NamedLevels map[string]Level
+
+	// Sampling thins out repetitive entries keyed by level and message. Nil
+	// disables sampling.
+	Sampling *Sampling
+
+	// Hooks run in order for every entry that passes level filtering and
+	// sampling. They can inspect the entry, change it or drop it. Loggers
+	// without hooks take the regular hot path.
+	Hooks []Hook
+
+	// Redact masks sensitive keys and value patterns in static fields and
+	// runtime keyvals. Nil disables redaction.
+	Redact *Redaction
+
+	// ErrorMode selects how error values are rendered. The zero value,
+	// ErrorModeMessage, logs the Error() string; ErrorModeRich adds the
+	// Unwrap chain and any stack trace the error carries.
+	ErrorMode ErrorMode
+
+	// Fatal configures the exit code, shutdown hooks and exit function used
+	// by Fatal. Owned outputs are always closed before exiting.
+	Fatal FatalOptions
+
+	// StackTraceLevel attaches the goroutine's stack to entries at or above
+	// the level: a `stack` array of "function file:line" frames in structured
+	// mode, an indented block below the line in console mode. pslog frames
+	// are skipped. Because the zero Level is DebugLevel, a zero
+	// StackTraceLevel leaves capture disabled unless EnableStackTrace is set;
+	// any other level enables it on its own.
+	StackTraceLevel Level
+
+	// EnableStackTrace turns on stack capture at StackTraceLevel even when
+	// that is the zero value, so "Debug and above" can be configured.
+	EnableStackTrace bool
+
 	// VerboseFields switches JSON keys from ts/lvl/msg to time/level/message.
 	VerboseFields bool
 
@@ -202,6 +250,11 @@ type Options struct {
 
 	// CallerKey sets the key used when CallerKeyval is enabled. Defaults to "fn".
 	CallerKey string
+
+	// CallerMode selects how the caller is rendered when CallerKeyval is
+	// enabled: function name (default), package.function, file:line or full
+	// path:line.
+	CallerMode CallerMode
 }
 
 // New constructs a pslog adapter configured for console output. ctx controls
@@ -382,6 +435,8 @@ func buildAdapter(ctx context.Context, w io.Writer, opts Options) Logger {
 	cfg := coreConfig{
 		writer:           w,
 		minLevel:         minLevel,
+		levelVar:         opts.LevelVar,
+		levelVarBase:     levelVarBase(opts.LevelVar),
 		includeTimestamp: includeTimestamp,
 		timeLayout:       timeFormat,
 		useUTC:           useUTC,
@@ -391,19 +446,43 @@ func buildAdapter(ctx context.Context, w io.Writer, opts Options) Logger {
 		timestampTrusted: timestampTrusted,
 		includeCaller:    opts.CallerKeyval,
 		callerKey:        callerKey,
-		callerKeyTrusted: stringTrustedASCII(callerKey),
+		callerKeyValue:   callerKeyValue(callerKey),
+		callerMode:       normalizeCallerMode(opts.CallerMode),
+	}
+	if len(opts.NamedLevels) > 0 {
+		cfg.namedLevels = maps.Clone(opts.NamedLevels)
 	}
+	cfg.sampler = newSampler(opts.Sampling)
+	cfg.redactor = newRedactor(opts.Redact)
+	cfg.errors = newErrorRenderer(opts.ErrorMode, mode == ModeConsole)
+	cfg.hooks = newHookChain(opts.Hooks, newStackCapture(opts.StackTraceLevel, opts.EnableStackTrace, mode == ModeConsole), cfg.redactor, cfg.errors)
+	if async, ok := w.(*AsyncWriter); ok {
+		cfg.async = async
+	}
+	cfg.fatal = newFatalConfig(opts.Fatal)
 
-	if mode == ModeStructured {
-		if colorEnabled {
-			return newJSONColorLogger(ctx, cfg, opts)
-		}
-		return newJSONPlainLogger(ctx, cfg, opts)
+	var logger Logger
+	switch {
+	case mode == ModeStructured && colorEnabled:
+		logger = newJSONColorLogger(ctx, cfg, opts)
+	case mode == ModeStructured:
+		logger = newJSONPlainLogger(ctx, cfg, opts)
+	case colorEnabled:
+		logger = newConsoleColorLogger(ctx, cfg, opts)
+	default:
+		logger = newConsolePlainLogger(ctx, cfg, opts)
 	}
-	if colorEnabled {
-		return newConsoleColorLogger(ctx, cfg, opts)
+	cfg.sampler.attachReporter(logger)
+	return logger
+}
+
+// callerKeyValue boxes the caller key once so appending it per entry does not
+// allocate.
+func callerKeyValue(key string) any {
+	if stringTrustedASCII(key) {
+		return TrustedString(key)
 	}
-	return newConsolePlainLogger(ctx, cfg, opts)
+	return key
 }
 
 func classifyLineLevel(line string) (Level, string) {
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"pkt.systems/pslog/ansi"
 )
@@ -48,10 +51,24 @@ func WithEnvWriter(w io.Writer) LoggerFromEnvOption {
 // controls runtime lifecycle; cancellation tears down logger-owned resources.
 //
 // Recognised variables are: {prefix}LEVEL, VERBOSE_FIELDS, CALLER_KEYVAL,
-// CALLER_KEY, MODE (console|structured|json), TIME_FORMAT, DISABLE_TIMESTAMP,
-// NO_COLOR, FORCE_COLOR, PALETTE, UTC, OUTPUT, and OUTPUT_FILE_MODE.
-// OUTPUT accepts stdout, stderr, default, a file path, or stdout+/stderr+/default+<path> to
-// tee. OUTPUT_FILE_MODE sets the mode for newly created output files.
+// CALLER_KEY, CALLER_MODE, MODE (console|structured|json), TIME_FORMAT, DISABLE_TIMESTAMP,
+// NO_COLOR, FORCE_COLOR, PALETTE, UTC, SAMPLING, REDACT_KEYS, ERROR_MODE,
+// OUTPUT, and OUTPUT_FILE_MODE.
+//
+// LEVEL accepts a default level optionally followed by comma-separated
+// name=level overrides for named loggers, e.g. "info,db=debug,db.pool=trace".
+// CALLER_MODE accepts function, package, file or path.
+// SAMPLING accepts a boolean or "first=100,thereafter=10,interval=1s,summary=10s".
+// REDACT_KEYS is a comma-separated list of key names or globs added to
+// Options.Redact. ERROR_MODE accepts message or rich. OUTPUT accepts stdout, stderr, default, a file path, or
+// stdout+/stderr+/default+<path> to tee; a path of the form
+// rotate:<path>?max=100MB&keep=7&age=7d&every=daily&compress=true writes a
+// RotatingFile and reopen:<path>?signal=hup&check=1s a ReopenFile.
+// syslog+tcp://host:514, syslog+udp://host:514 or syslog+unix:///dev/log,
+// optionally followed by ?facility=local0&app=name&format=sd|json, sends
+// uncoloured structured lines to a SyslogWriter, and journald or
+// journald:<socket> to a JournalWriter.
+// OUTPUT_FILE_MODE sets the mode for newly created output files.
 func LoggerFromEnv(ctx context.Context, opts ...LoggerFromEnvOption) Logger {
 	cfg := loggerFromEnvConfig{prefix: "LOG_"}
 	for _, opt := range opts {
@@ -66,8 +83,20 @@ func LoggerFromEnv(ctx context.Context, opts ...LoggerFromEnvOption) Logger {
 	}
 	prefix := cfg.prefix
 	if value, ok := lookupEnv(prefix, "LEVEL"); ok {
-		if level, ok := ParseLevel(value); ok {
+		level, hasLevel, named := parseEnvLevelSpec(value)
+		if hasLevel {
 			resolvedOpts.MinLevel = level
+			if resolvedOpts.LevelVar != nil {
+				resolvedOpts.LevelVar.Set(level)
+			}
+		}
+		if len(named) > 0 {
+			merged := maps.Clone(resolvedOpts.NamedLevels)
+			if merged == nil {
+				merged = make(map[string]Level, len(named))
+			}
+			maps.Copy(merged, named)
+			resolvedOpts.NamedLevels = merged
 		}
 	}
 	if value, ok := lookupEnv(prefix, "VERBOSE_FIELDS"); ok {
@@ -85,6 +114,11 @@ func LoggerFromEnv(ctx context.Context, opts ...LoggerFromEnvOption) Logger {
 			resolvedOpts.CallerKey = parsed
 		}
 	}
+	if value, ok := lookupEnv(prefix, "CALLER_MODE"); ok {
+		if parsed, ok := parseEnvCallerMode(value); ok {
+			resolvedOpts.CallerMode = parsed
+		}
+	}
 	if value, ok := lookupEnv(prefix, "MODE"); ok {
 		if parsed, ok := parseEnvMode(value); ok {
 			resolvedOpts.Mode = parsed
@@ -118,6 +152,26 @@ func LoggerFromEnv(ctx context.Context, opts ...LoggerFromEnvOption) Logger {
 			resolvedOpts.UTC = parsed
 		}
 	}
+	if value, ok := lookupEnv(prefix, "REDACT_KEYS"); ok {
+		if keys := parseEnvList(value); len(keys) > 0 {
+			redact := Redaction{}
+			if resolvedOpts.Redact != nil {
+				redact = *resolvedOpts.Redact
+			}
+			redact.Keys = append(slices.Clip(redact.Keys), keys...)
+			resolvedOpts.Redact = &redact
+		}
+	}
+	if value, ok := lookupEnv(prefix, "ERROR_MODE"); ok {
+		if parsed, ok := parseEnvErrorMode(value); ok {
+			resolvedOpts.ErrorMode = parsed
+		}
+	}
+	if value, ok := lookupEnv(prefix, "SAMPLING"); ok {
+		if parsed, ok := parseEnvSampling(value); ok {
+			resolvedOpts.Sampling = parsed
+		}
+	}
 	outputFileMode := defaultOutputFileMode
 	outputFileModeValue := ""
 	var outputFileModeErr error
@@ -140,6 +194,16 @@ func LoggerFromEnv(ctx context.Context, opts ...LoggerFromEnvOption) Logger {
 			writer = resolved
 		}
 	}
+	if needsStructuredLines(writer) {
+		// SyslogWriter and JournalWriter read the level and fields from JSON
+		// lines.
+		resolvedOpts.Mode = ModeStructured
+		resolvedOpts.NoColor = true
+		resolvedOpts.ForceColor = false
+		if resolvedOpts.CallerKey != "" {
+			setJournalCallerKey(writer, resolvedOpts.CallerKey)
+		}
+	}
 	logger := NewWithOptions(ctx, writer, resolvedOpts)
 	if outputFileModeErr != nil {
 		logger.With(outputFileModeErr).Error("logger.output.file_mode.invalid", "output_file_mode", outputFileModeValue)
@@ -157,6 +221,104 @@ func lookupEnv(prefix, key string) (string, bool) {
 	return os.LookupEnv(prefix + key)
 }
 
+// parseEnvLevelSpec parses a LEVEL value such as "info,db=debug,db.pool=trace"
+// into the default level and per-name overrides. Invalid entries are skipped.
+func parseEnvLevelSpec(value string) (Level, bool, map[string]Level) {
+	var (
+		level    Level
+		hasLevel bool
+		named    map[string]Level
+	)
+	for part := range strings.SplitSeq(value, ",") {
+		name, levelValue, isNamed := strings.Cut(part, "=")
+		if !isNamed {
+			if parsed, ok := ParseLevel(part); ok {
+				level, hasLevel = parsed, true
+			}
+			continue
+		}
+		name = strings.TrimSpace(name)
+		parsed, ok := ParseLevel(levelValue)
+		if name == "" || !ok {
+			continue
+		}
+		if named == nil {
+			named = make(map[string]Level)
+		}
+		named[name] = parsed
+	}
+	return level, hasLevel, named
+}
+
+// parseEnvSampling parses a SAMPLING value. Boolean values (and on/off)
+// switch sampling off or on with defaults; otherwise the value is a comma-separated list of
+// first=N, thereafter=M, interval=<duration> and summary=<duration>.
+func parseEnvSampling(value string) (*Sampling, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "on":
+		return &Sampling{}, true
+	case "off":
+		return nil, true
+	}
+	if enabled, ok := parseEnvBool(value); ok {
+		if !enabled {
+			return nil, true
+		}
+		return &Sampling{}, true
+	}
+	sampling := &Sampling{}
+	for part := range strings.SplitSeq(value, ",") {
+		key, raw, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, false
+		}
+		raw = strings.TrimSpace(raw)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "first":
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return nil, false
+			}
+			if n == 0 {
+				// Sampling.First uses zero for the default.
+				n = -1
+			}
+			sampling.First = n
+		case "thereafter":
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return nil, false
+			}
+			sampling.Thereafter = n
+		case "interval":
+			d, err := time.ParseDuration(raw)
+			if err != nil || d < 0 {
+				return nil, false
+			}
+			sampling.Interval = d
+		case "summary":
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return nil, false
+			}
+			sampling.SummaryInterval = d
+		default:
+			return nil, false
+		}
+	}
+	return sampling, true
+}
+
+func parseEnvList(value string) []string {
+	var out []string
+	for part := range strings.SplitSeq(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
 func parseEnvBool(value string) (bool, bool) {
 	parsed, err := strconv.ParseBool(strings.TrimSpace(value))
 	if err != nil {
@@ -176,6 +338,32 @@ func parseEnvMode(value string) (Mode, bool) {
 	}
 }
 
+func parseEnvCallerMode(value string) (CallerMode, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "function", "func", "fn":
+		return CallerModeFunction, true
+	case "package", "package.function", "pkg":
+		return CallerModePackage, true
+	case "file", "short", "file:line":
+		return CallerModeShortFile, true
+	case "path", "full", "path:line":
+		return CallerModeFullPath, true
+	default:
+		return CallerModeFunction, false
+	}
+}
+
+func parseEnvErrorMode(value string) (ErrorMode, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "message":
+		return ErrorModeMessage, true
+	case "rich":
+		return ErrorModeRich, true
+	default:
+		return ErrorModeMessage, false
+	}
+}
+
 func parseEnvFileMode(value string) (os.FileMode, bool) {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
@@ -265,10 +453,75 @@ func writerFromEnvOutput(value string, base io.Writer, outputFileMode os.FileMod
 	}
 }
 
-func openLogOutputFile(path string, outputFileMode os.FileMode) (*os.File, error) {
+// openLogOutputFile opens the file part of an OUTPUT value: a plain path,
+// rotate:<path>?<options> for a RotatingFile, reopen:<path>?<options> for a
+// ReopenFile, a syslog URL for a SyslogWriter or journald[:<socket>] for a
+// JournalWriter.
+func openLogOutputFile(path string, outputFileMode os.FileMode) (io.WriteCloser, error) {
+	if spec, ok := cutPrefixFold(path, "rotate:"); ok {
+		rotatePath, opts, err := parseRotateSpec(spec, outputFileMode)
+		if err != nil {
+			return nil, fmt.Errorf("open log output %q: %w", path, err)
+		}
+		rotating, err := NewRotatingFile(rotatePath, opts)
+		if err != nil {
+			return nil, fmt.Errorf("open log output %q: %w", path, err)
+		}
+		return rotating, nil
+	}
+	if spec, ok := cutPrefixFold(path, "reopen:"); ok {
+		reopenPath, opts, err := parseReopenSpec(spec, outputFileMode)
+		if err != nil {
+			return nil, fmt.Errorf("open log output %q: %w", path, err)
+		}
+		reopening, err := NewReopenFile(reopenPath, opts)
+		if err != nil {
+			return nil, fmt.Errorf("open log output %q: %w", path, err)
+		}
+		return reopening, nil
+	}
+	if spec, ok := isJournalOutput(path); ok {
+		journal, err := openJournalOutput(spec)
+		if err != nil {
+			return nil, fmt.Errorf("open log output %q: %w", path, err)
+		}
+		return journal, nil
+	}
+	if lowered := strings.ToLower(path); strings.HasPrefix(lowered, "syslog://") || strings.HasPrefix(lowered, "syslog+") {
+		syslog, err := openSyslogOutput(path)
+		if err != nil {
+			return nil, fmt.Errorf("open log output %q: %w", path, err)
+		}
+		return syslog, nil
+	}
 	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, outputFileMode)
 	if err != nil {
 		return nil, fmt.Errorf("open log output %q: %w", path, err)
 	}
 	return file, nil
 }
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+// needsStructuredLines reports whether w, or a writer pslog wraps in it, parses
+// JSON lines.
+func needsStructuredLines(w io.Writer) bool {
+	switch v := w.(type) {
+	case *SyslogWriter, *JournalWriter:
+		return true
+	case *ownedOutput:
+		return needsStructuredLines(v.writer)
+	case *teeWriter:
+		for _, inner := range v.writers {
+			if needsStructuredLines(inner) {
+				return true
+			}
+		}
+	}
+	return false
+}
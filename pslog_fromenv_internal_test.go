@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLookupEnvPrefix(t *testing.T) {
@@ -60,6 +61,45 @@ func TestParseEnvMode(t *testing.T) {
 	}
 }
 
+func TestParseEnvLevelSpec(t *testing.T) {
+	level, ok, named := parseEnvLevelSpec(" info , db=debug,db.pool = trace,bad=loud,=warn")
+	if !ok || level != InfoLevel {
+		t.Fatalf("expected default info, got %v,%v", level, ok)
+	}
+	want := map[string]Level{"db": DebugLevel, "db.pool": TraceLevel}
+	if len(named) != len(want) {
+		t.Fatalf("unexpected overrides: %v", named)
+	}
+	for name, lvl := range want {
+		if named[name] != lvl {
+			t.Fatalf("override %q = %v, want %v", name, named[name], lvl)
+		}
+	}
+
+	if _, ok, named := parseEnvLevelSpec("db=warn"); ok || named["db"] != WarnLevel {
+		t.Fatalf("expected override-only spec without default, got %v %v", ok, named)
+	}
+}
+
+func TestParseEnvSampling(t *testing.T) {
+	if sampling, ok := parseEnvSampling("off"); !ok || sampling != nil {
+		t.Fatalf("expected off to disable sampling, got %v,%v", sampling, ok)
+	}
+	if sampling, ok := parseEnvSampling("true"); !ok || sampling == nil || *sampling != (Sampling{}) {
+		t.Fatalf("expected true to enable default sampling, got %v,%v", sampling, ok)
+	}
+	sampling, ok := parseEnvSampling("first=10, thereafter=5,interval=2s,summary=1m")
+	want := Sampling{First: 10, Thereafter: 5, Interval: 2 * time.Second, SummaryInterval: time.Minute}
+	if !ok || sampling == nil || *sampling != want {
+		t.Fatalf("unexpected sampling %v,%v", sampling, ok)
+	}
+	for _, invalid := range []string{"first=x", "rate=2", "interval=-1s", "10"} {
+		if _, ok := parseEnvSampling(invalid); ok {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
 func TestWriterFromEnvOutputDefaultKeepsBase(t *testing.T) {
 	base := &bytes.Buffer{}
 	writer, err := writerFromEnvOutput("default", base, defaultOutputFileMode)
@@ -111,6 +151,23 @@ func TestWriterFromEnvOutputFile(t *testing.T) {
 	}
 }
 
+func TestWriterFromEnvOutputJournaldPrefixedFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, name := range []string{"journald.log", "Journald-app.log"} {
+		writer, err := writerFromEnvOutput(name, nil, defaultOutputFileMode)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if _, err := writer.Write([]byte("file")); err != nil {
+			t.Fatalf("%s: write failed: %v", name, err)
+		}
+		closeWriter(t, writer)
+		if data, err := os.ReadFile(name); err != nil || string(data) != "file" {
+			t.Fatalf("%s: expected file output, got %q (%v)", name, data, err)
+		}
+	}
+}
+
 func TestWriterFromEnvOutputDefaultTee(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "tee.log")
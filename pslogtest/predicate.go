@@ -0,0 +1,333 @@
+package pslogtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"pkt.systems/pslog"
+)
+
+// Predicate selects recorded entries.
+type Predicate struct {
+	desc  string
+	match func(Entry) bool
+}
+
+// Match reports whether entry satisfies the predicate.
+func (p Predicate) Match(entry Entry) bool {
+	return p.match == nil || p.match(entry)
+}
+
+// String describes the predicate in query syntax.
+func (p Predicate) String() string {
+	return p.desc
+}
+
+// Func builds a predicate from fn; desc is used in failure messages.
+func Func(desc string, fn func(Entry) bool) Predicate {
+	return Predicate{desc: desc, match: fn}
+}
+
+// Level matches entries at level.
+func Level(level pslog.Level) Predicate {
+	return Func("level="+pslog.LevelString(level), func(e Entry) bool { return e.Level == level })
+}
+
+// MinLevel matches entries at level or above, excluding NoLevel entries.
+func MinLevel(level pslog.Level) Predicate {
+	return Func("level>="+pslog.LevelString(level), func(e Entry) bool {
+		return e.Level >= level && e.Level < pslog.NoLevel
+	})
+}
+
+// Message matches entries whose message is msg.
+func Message(msg string) Predicate {
+	return Func("msg="+strconv.Quote(msg), func(e Entry) bool { return e.Message == msg })
+}
+
+// MessageContains matches entries whose message contains substr.
+func MessageContains(substr string) Predicate {
+	return Func("msg~"+strconv.Quote(substr), func(e Entry) bool { return strings.Contains(e.Message, substr) })
+}
+
+// Has matches entries carrying the field key; see Fields.Get.
+func Has(key string) Predicate {
+	return Func(key, func(e Entry) bool {
+		_, ok := e.Get(key)
+		return ok
+	})
+}
+
+// Equal matches entries whose field key equals value as pslog renders it:
+// Equal("status", 200), Equal("user.id", "42") and Equal("error", err)
+// compare against the logged number, string and error message.
+func Equal(key string, value any) Predicate {
+	want := expectedString(value)
+	return Func(key+"="+strconv.Quote(want), func(e Entry) bool {
+		got, ok := e.Get(key)
+		return ok && valueString(got) == want
+	})
+}
+
+// Not negates p.
+func Not(p Predicate) Predicate {
+	return Func("!("+p.desc+")", func(e Entry) bool { return !p.Match(e) })
+}
+
+// Or matches entries satisfying any of preds.
+func Or(preds ...Predicate) Predicate {
+	descs := make([]string, len(preds))
+	for i, p := range preds {
+		descs[i] = p.desc
+	}
+	return Func("("+strings.Join(descs, " | ")+")", func(e Entry) bool {
+		for _, p := range preds {
+			if p.Match(e) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Query parses a whitespace separated list of terms that must all hold:
+//
+//	level=error msg~timeout user.id=42 !retry status>=500 attempt
+//
+// A term is key=value, key!=value, key~substring, key<n, key<=n, key>n,
+// key>=n, a bare key (field present) or !key (field absent). Values may be
+// double quoted. The keys level (alias lvl) and msg (alias message) address
+// the entry's level and message; level also supports the ordering operators.
+func Query(expr string) (Predicate, error) {
+	terms, err := splitTerms(expr)
+	if err != nil {
+		return Predicate{}, fmt.Errorf("pslogtest: query %q: %w", expr, err)
+	}
+	preds := make([]Predicate, 0, len(terms))
+	for _, term := range terms {
+		pred, err := parseTerm(term)
+		if err != nil {
+			return Predicate{}, fmt.Errorf("pslogtest: query %q: %w", expr, err)
+		}
+		preds = append(preds, pred)
+	}
+	return Func(expr, func(e Entry) bool { return matchAll(e, preds) }), nil
+}
+
+// MustQuery is like Query but panics when expr is invalid.
+func MustQuery(expr string) Predicate {
+	pred, err := Query(expr)
+	if err != nil {
+		panic(err)
+	}
+	return pred
+}
+
+var queryOperators = []string{"!=", ">=", "<=", "=", "~", ">", "<"}
+
+func parseTerm(term string) (Predicate, error) {
+	for i := 0; i < len(term); i++ {
+		if term[i] == '"' {
+			break
+		}
+		for _, op := range queryOperators {
+			if !strings.HasPrefix(term[i:], op) {
+				continue
+			}
+			key := term[:i]
+			if key == "" {
+				return Predicate{}, fmt.Errorf("missing key in %q", term)
+			}
+			value, err := unquote(term[i+len(op):])
+			if err != nil {
+				return Predicate{}, fmt.Errorf("term %q: %w", term, err)
+			}
+			return comparison(term, key, op, value)
+		}
+	}
+	if key, ok := strings.CutPrefix(term, "!"); ok {
+		if key == "" {
+			return Predicate{}, fmt.Errorf("missing key in %q", term)
+		}
+		return Func(term, Not(Has(key)).match), nil
+	}
+	return Has(term), nil
+}
+
+func comparison(term, key, op, value string) (Predicate, error) {
+	switch key {
+	case "level", "lvl":
+		level, ok := pslog.ParseLevel(value)
+		if !ok {
+			return Predicate{}, fmt.Errorf("term %q: unknown level %q", term, value)
+		}
+		return Func(term, func(e Entry) bool {
+			if e.Level == pslog.NoLevel && op != "=" && op != "!=" {
+				return false
+			}
+			return compareOrdered(op, int(e.Level), int(level))
+		}), nil
+	case "msg", "message":
+		return Func(term, func(e Entry) bool { return compareString(op, e.Message, value) }), nil
+	}
+	var number float64
+	if op == "<" || op == "<=" || op == ">" || op == ">=" {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return Predicate{}, fmt.Errorf("term %q: %q is not a number", term, value)
+		}
+		number = n
+	}
+	return Func(term, func(e Entry) bool {
+		got, ok := e.Get(key)
+		if !ok {
+			return op == "!="
+		}
+		switch op {
+		case "<", "<=", ">", ">=":
+			n, ok := got.(json.Number)
+			if !ok {
+				return false
+			}
+			f, err := n.Float64()
+			return err == nil && compareOrdered(op, f, number)
+		}
+		return compareString(op, valueString(got), value)
+	}), nil
+}
+
+func compareOrdered[T int | float64](op string, got, want T) bool {
+	switch op {
+	case "=":
+		return got == want
+	case "!=":
+		return got != want
+	case "<":
+		return got < want
+	case "<=":
+		return got <= want
+	case ">":
+		return got > want
+	case ">=":
+		return got >= want
+	}
+	return false
+}
+
+func compareString(op, got, want string) bool {
+	switch op {
+	case "=":
+		return got == want
+	case "!=":
+		return got != want
+	case "~":
+		return strings.Contains(got, want)
+	}
+	return false
+}
+
+// splitTerms splits expr on whitespace outside double quotes.
+func splitTerms(expr string) ([]string, error) {
+	var terms []string
+	var current strings.Builder
+	quoted := false
+	escaped := false
+	for _, r := range expr {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	return terms, nil
+}
+
+func unquote(value string) (string, error) {
+	if strings.HasPrefix(value, `"`) {
+		return strconv.Unquote(value)
+	}
+	return value, nil
+}
+
+func matchAll(entry Entry, preds []Predicate) bool {
+	for _, p := range preds {
+		if !p.Match(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func describe(preds []Predicate) string {
+	if len(preds) == 0 {
+		return "(any)"
+	}
+	descs := make([]string, len(preds))
+	for i, p := range preds {
+		descs[i] = p.desc
+	}
+	return strings.Join(descs, " ")
+}
+
+// valueString renders a decoded JSON value the way queries compare it.
+func valueString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// expectedString renders a Go value the way pslog would log it.
+func expectedString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case nil:
+		return "null"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return s
+	}
+	return string(data)
+}
@@ -0,0 +1,170 @@
+package pslogtest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+	"pkt.systems/pslog/pslogtest"
+)
+
+// fakeTB captures failures so assertion helpers can be tested.
+type fakeTB struct {
+	testing.TB
+	logs   []string
+	errors []string
+	fatals []string
+}
+
+func (f *fakeTB) Helper()         {}
+func (f *fakeTB) Log(args ...any) { f.logs = append(f.logs, fmt.Sprint(args...)) }
+func (f *fakeTB) Errorf(format string, a ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, a...))
+}
+func (f *fakeTB) Fatalf(format string, a ...any) {
+	f.fatals = append(f.fatals, fmt.Sprintf(format, a...))
+}
+
+func TestRecorderKeepsFieldOrderAndWithFields(t *testing.T) {
+	rec := pslogtest.New()
+	logger := pslog.WithGroup(rec.Named("api").With("svc", "billing", "region", "eu"), "http")
+	logger.Info("request", "method", "GET", "status", 200)
+	rec.Trace("low")
+
+	entries := rec.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != pslog.InfoLevel || entry.Message != "request" {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	var keys []string
+	for _, f := range entry.Fields {
+		keys = append(keys, f.Key)
+	}
+	if got := strings.Join(keys, ","); got != "logger,svc,region,http" {
+		t.Fatalf("unexpected field order %q", got)
+	}
+	if v, ok := entry.Get("http.status"); !ok || fmt.Sprint(v) != "200" {
+		t.Fatalf("expected http.status=200, got %v %v", v, ok)
+	}
+	if entries[1].Level != pslog.TraceLevel {
+		t.Fatalf("expected trace entry to be recorded, got %v", entries[1].Level)
+	}
+}
+
+func TestRecorderPredicatesAndQueries(t *testing.T) {
+	rec := pslogtest.New()
+	rec.With("user", "ada").Warn("slow request", "ms", 1500, "path", "/v1/items")
+	rec.Error("request failed", "error", errors.New("timeout"))
+	rec.Info("done", "retry", false)
+
+	rec.RequireEntry(t, pslogtest.Level(pslog.WarnLevel), pslogtest.Equal("ms", 1500), pslogtest.Equal("user", "ada"))
+	rec.RequireEntry(t, pslogtest.MessageContains("failed"), pslogtest.Equal("error", errors.New("timeout")))
+	rec.RequireEntry(t, pslogtest.MustQuery(`level>=warn ms>1000 path~items !error msg="slow request"`))
+	rec.RequireEntry(t, pslogtest.MustQuery(`retry=false level<warn`))
+	rec.RequireNoEntry(t, pslogtest.MustQuery("level=fatal"))
+
+	if got := len(rec.Filter(pslogtest.MinLevel(pslog.WarnLevel))); got != 2 {
+		t.Fatalf("expected 2 entries at warn or above, got %d", got)
+	}
+	if got := len(rec.Filter(pslogtest.Or(pslogtest.Has("retry"), pslogtest.Has("ms")))); got != 2 {
+		t.Fatalf("expected 2 entries from Or, got %d", got)
+	}
+	for _, bad := range []string{"", `msg="open`, "=x", "ms>abc", "level=loud"} {
+		if _, err := pslogtest.Query(bad); err == nil {
+			t.Fatalf("expected query %q to fail", bad)
+		}
+	}
+}
+
+func TestRequireEntryAndNoErrorsReportFailures(t *testing.T) {
+	rec := pslogtest.New()
+	rec.Info("hello")
+	rec.Error("boom")
+
+	tb := &fakeTB{}
+	rec.RequireEntry(tb, pslogtest.Message("missing"))
+	if len(tb.fatals) != 1 || !strings.Contains(tb.fatals[0], `"msg":"hello"`) {
+		t.Fatalf("expected failure listing recorded entries, got %q", tb.fatals)
+	}
+	rec.NoErrors(tb)
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "boom") {
+		t.Fatalf("expected NoErrors to report the error entry, got %q", tb.errors)
+	}
+
+	rec.Reset()
+	rec.NoErrors(t)
+	if rec.Len() != 0 {
+		t.Fatalf("expected Reset to drop entries")
+	}
+}
+
+func TestRecorderInterceptsFatalAndPanic(t *testing.T) {
+	rec := pslogtest.New(pslogtest.WithOptions(pslog.Options{Fatal: pslog.FatalOptions{ExitCode: 4}}))
+	rec.With("job", 1).Fatal("giving up")
+	rec.Panic("unreachable state")
+
+	if code, ok := rec.Exited(); !ok || code != 4 {
+		t.Fatalf("expected intercepted exit with code 4, got %d %v", code, ok)
+	}
+	if got := rec.Panicked(); len(got) != 1 || got[0] != "unreachable state" {
+		t.Fatalf("unexpected panics %q", got)
+	}
+	rec.RequireEntry(t, pslogtest.Level(pslog.FatalLevel), pslogtest.Equal("job", 1))
+	rec.RequireEntry(t, pslogtest.Level(pslog.PanicLevel))
+
+	panicking := pslogtest.New(pslogtest.WithPanics())
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("expected panic with message, got %v", r)
+		}
+		panicking.RequireEntry(t, pslogtest.Message("boom"))
+	}()
+	panicking.Panic("boom")
+}
+
+func TestNewTBWritesThroughLog(t *testing.T) {
+	tb := &fakeTB{}
+	logger := pslogtest.NewTB(tb)
+	logger.Debug("visible", "k", "v")
+	logger.Fatal("stop")
+
+	if len(tb.logs) != 2 || !strings.Contains(tb.logs[0], "visible") || !strings.Contains(tb.logs[0], "k=v") {
+		t.Fatalf("unexpected logs %q", tb.logs)
+	}
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "exit code 1") {
+		t.Fatalf("expected Fatal to fail the test, got %q", tb.errors)
+	}
+}
+
+func TestRecorderKeepsReusedLevelAndMessageKeysAsFields(t *testing.T) {
+	rec := pslogtest.New()
+	rec.Info("login", "msg", "from user", "level", "admin", "lvl", "warn", "n", 3)
+
+	entry := rec.RequireEntry(t, pslogtest.Message("login"))
+	if entry.Level != pslog.InfoLevel {
+		t.Fatalf("expected info level, got %v", entry.Level)
+	}
+	var keys []string
+	for _, f := range entry.Fields {
+		keys = append(keys, f.Key)
+	}
+	if got := strings.Join(keys, ","); got != "msg,level,lvl,n" {
+		t.Fatalf("expected reused keys to stay fields, got %q", got)
+	}
+	if v, _ := entry.Get("n"); v != json.Number("3") {
+		t.Fatalf("expected numbers to be recorded as json.Number, got %#v", v)
+	}
+
+	verbose := pslogtest.New(pslogtest.WithOptions(pslog.Options{VerboseFields: true}))
+	verbose.Warn("login", "lvl", "error")
+	entry = verbose.RequireEntry(t, pslogtest.Message("login"))
+	if entry.Level != pslog.WarnLevel || len(entry.Fields) != 1 || entry.Fields[0].Key != "lvl" {
+		t.Fatalf("unexpected verbose entry %+v", entry)
+	}
+}
@@ -0,0 +1,493 @@
+// Package pslogtest records pslog entries in memory so tests can assert on
+// what the code under test logged without parsing bytes. A Recorder is a
+// pslog.Logger backed by the structured JSON emitter: entries keep the field
+// order, With fields, groups, names and error rendering exactly as pslog
+// produces them. Fatal and Panic are intercepted and recorded instead of
+// exiting or panicking.
+//
+// NewTB returns a console logger that writes through testing.TB.Log so log
+// output shows up next to the test that produced it.
+package pslogtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+// Field is one key/value pair of a recorded entry. Values are decoded from
+// the emitted JSON line, not kept as the values that were logged: strings,
+// json.Number, bool, nil, Fields for nested objects (groups, marshalers) and
+// []any for arrays. A logged int 3 is recorded as json.Number("3"), so compare
+// numbers with its Int64 or Float64 methods, or use the query predicates,
+// which compare them numerically.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Fields is an ordered list of fields.
+type Fields []Field
+
+// Get returns the value stored under key. Dotted keys such as "http.method"
+// descend into nested objects when no field carries the literal key.
+func (f Fields) Get(key string) (any, bool) {
+	for i := len(f) - 1; i >= 0; i-- {
+		if f[i].Key == key {
+			return f[i].Value, true
+		}
+	}
+	head, rest, ok := strings.Cut(key, ".")
+	if !ok {
+		return nil, false
+	}
+	for i := len(f) - 1; i >= 0; i-- {
+		if f[i].Key != head {
+			continue
+		}
+		if nested, ok := f[i].Value.(Fields); ok {
+			return nested.Get(rest)
+		}
+	}
+	return nil, false
+}
+
+// MarshalJSON renders the fields as a JSON object in their recorded order.
+func (f Fields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range f {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Entry is one recorded log entry.
+type Entry struct {
+	// Level is the entry's level; NoLevel when the entry carried none.
+	Level pslog.Level
+	// Message is the entry's message.
+	Message string
+	// Fields holds every other field in emitted order: the logger name, With
+	// fields, then the keyvals of the log call. Only the first level and
+	// message keys are taken as Level and Message; a later field that reuses
+	// those keys stays in Fields.
+	Fields Fields
+	// Line is the JSON line pslog wrote.
+	Line string
+}
+
+// Get returns the value of the field key; see Fields.Get.
+func (e Entry) Get(key string) (any, bool) {
+	return e.Fields.Get(key)
+}
+
+// String returns the recorded JSON line.
+func (e Entry) String() string {
+	return e.Line
+}
+
+// Option customizes a Recorder.
+type Option func(*config)
+
+type config struct {
+	options  pslog.Options
+	minLevel pslog.Level
+	panics   bool
+}
+
+// WithOptions seeds the underlying logger with opts. Mode, NoColor,
+// DisableTimestamp, MinLevel and Fatal.Exit are managed by the Recorder.
+func WithOptions(opts pslog.Options) Option {
+	return func(cfg *config) {
+		cfg.options = opts
+	}
+}
+
+// WithMinLevel sets the lowest level that is recorded. Defaults to
+// TraceLevel.
+func WithMinLevel(level pslog.Level) Option {
+	return func(cfg *config) {
+		cfg.minLevel = level
+	}
+}
+
+// WithPanics makes Panic panic after the entry has been recorded, as the
+// regular loggers do.
+func WithPanics() Option {
+	return func(cfg *config) {
+		cfg.panics = true
+	}
+}
+
+type state struct {
+	mu       sync.Mutex
+	entries  []Entry
+	partial  []byte
+	errs     []error
+	exitCode int
+	exited   bool
+	// levelKey and msgKey are the keys the emitter writes the level and
+	// message under, depending on Options.VerboseFields.
+	levelKey string
+	msgKey   string
+	panics   bool
+	panicked []string
+}
+
+// Write parses each complete line into an Entry.
+func (s *state) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.partial = append(s.partial, p...)
+	for {
+		idx := bytes.IndexByte(s.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		line := string(s.partial[:idx])
+		s.partial = s.partial[idx+1:]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entry, err := parseEntry(line, s.levelKey, s.msgKey)
+		if err != nil {
+			s.errs = append(s.errs, err)
+			continue
+		}
+		s.entries = append(s.entries, entry)
+	}
+	return len(p), nil
+}
+
+func (s *state) exit(code int) {
+	s.mu.Lock()
+	s.exitCode = code
+	s.exited = true
+	s.mu.Unlock()
+}
+
+// Recorder is a pslog.Logger that records every entry in memory. Loggers
+// derived from it (With, Named, WithGroup, LogLevel, ...) are Recorders too
+// and share the recorded entries, so assertions can be made through any of
+// them. It is safe for concurrent use.
+type Recorder struct {
+	logger pslog.Logger
+	state  *state
+}
+
+var _ pslog.Logger = (*Recorder)(nil)
+
+// New returns an empty Recorder.
+func New(opts ...Option) *Recorder {
+	cfg := config{minLevel: pslog.TraceLevel}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	st := &state{panics: cfg.panics, levelKey: "lvl", msgKey: "msg"}
+	if cfg.options.VerboseFields {
+		st.levelKey, st.msgKey = "level", "message"
+	}
+	options := cfg.options
+	options.Mode = pslog.ModeStructured
+	options.NoColor = true
+	options.ForceColor = false
+	options.DisableTimestamp = true
+	options.MinLevel = cfg.minLevel
+	options.Fatal.Exit = st.exit
+	// pslog skips its own frames, Recorder methods included, when resolving
+	// the caller.
+	logger := pslog.NewWithOptions(context.Background(), st, options)
+	return &Recorder{logger: logger, state: st}
+}
+
+func (r *Recorder) derive(logger pslog.Logger) pslog.Logger {
+	return &Recorder{logger: logger, state: r.state}
+}
+
+// Trace records msg at TraceLevel.
+func (r *Recorder) Trace(msg string, keyvals ...any) { r.logger.Trace(msg, keyvals...) }
+
+// Debug records msg at DebugLevel.
+func (r *Recorder) Debug(msg string, keyvals ...any) { r.logger.Debug(msg, keyvals...) }
+
+// Info records msg at InfoLevel.
+func (r *Recorder) Info(msg string, keyvals ...any) { r.logger.Info(msg, keyvals...) }
+
+// Warn records msg at WarnLevel.
+func (r *Recorder) Warn(msg string, keyvals ...any) { r.logger.Warn(msg, keyvals...) }
+
+// Error records msg at ErrorLevel.
+func (r *Recorder) Error(msg string, keyvals ...any) { r.logger.Error(msg, keyvals...) }
+
+// Fatal records msg at FatalLevel, runs any Options.Fatal shutdown hooks and
+// records the exit code, then returns instead of exiting. See Exited.
+func (r *Recorder) Fatal(msg string, keyvals ...any) {
+	r.logger.Fatal(msg, keyvals...)
+}
+
+// Panic records msg at PanicLevel. It only panics when the Recorder was built
+// with WithPanics. See Panicked.
+func (r *Recorder) Panic(msg string, keyvals ...any) {
+	r.logger.Log(pslog.PanicLevel, msg, keyvals...)
+	r.state.mu.Lock()
+	r.state.panicked = append(r.state.panicked, msg)
+	panics := r.state.panics
+	r.state.mu.Unlock()
+	if panics {
+		panic(msg)
+	}
+}
+
+// Log records msg at level.
+func (r *Recorder) Log(level pslog.Level, msg string, keyvals ...any) {
+	r.logger.Log(level, msg, keyvals...)
+}
+
+// With returns a Recorder that adds keyvals to every entry.
+func (r *Recorder) With(keyvals ...any) pslog.Logger { return r.derive(r.logger.With(keyvals...)) }
+
+// WithLogLevel returns a Recorder that adds a `loglevel` field.
+func (r *Recorder) WithLogLevel() pslog.Logger { return r.derive(r.logger.WithLogLevel()) }
+
+// LogLevel returns a Recorder with a different minimum level.
+func (r *Recorder) LogLevel(level pslog.Level) pslog.Logger {
+	return r.derive(r.logger.LogLevel(level))
+}
+
+// LogLevelFromEnv returns a Recorder whose level is read from key.
+func (r *Recorder) LogLevelFromEnv(key string) pslog.Logger {
+	return r.derive(r.logger.LogLevelFromEnv(key))
+}
+
+// Named returns a Recorder whose entries carry the extended logger name.
+func (r *Recorder) Named(name string) pslog.Logger { return r.derive(pslog.Named(r.logger, name)) }
+
+// WithGroup returns a Recorder that nests later fields under name.
+func (r *Recorder) WithGroup(name string) pslog.Logger {
+	return r.derive(pslog.WithGroup(r.logger, name))
+}
+
+// Entries returns a copy of the recorded entries in logging order.
+func (r *Recorder) Entries() []Entry {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	return append([]Entry(nil), r.state.entries...)
+}
+
+// Len returns the number of recorded entries.
+func (r *Recorder) Len() int {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	return len(r.state.entries)
+}
+
+// Filter returns the entries matching every predicate.
+func (r *Recorder) Filter(preds ...Predicate) []Entry {
+	var out []Entry
+	for _, entry := range r.Entries() {
+		if matchAll(entry, preds) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// Reset drops the recorded entries and the Fatal and Panic state.
+func (r *Recorder) Reset() {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	r.state.entries = nil
+	r.state.partial = nil
+	r.state.errs = nil
+	r.state.exitCode = 0
+	r.state.exited = false
+	r.state.panicked = nil
+}
+
+// Exited reports whether Fatal was called and the exit code it would have
+// used.
+func (r *Recorder) Exited() (int, bool) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	return r.state.exitCode, r.state.exited
+}
+
+// Panicked returns the messages passed to Panic.
+func (r *Recorder) Panicked() []string {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	return append([]string(nil), r.state.panicked...)
+}
+
+// RequireEntry returns the first entry matching every predicate. When none
+// matches it fails the test with tb.Fatalf, listing what was recorded.
+func (r *Recorder) RequireEntry(tb testing.TB, preds ...Predicate) Entry {
+	tb.Helper()
+	entries := r.Entries()
+	for _, entry := range entries {
+		if matchAll(entry, preds) {
+			return entry
+		}
+	}
+	tb.Fatalf("pslogtest: no entry matches %s; recorded:\n%s", describe(preds), dump(entries))
+	return Entry{}
+}
+
+// RequireNoEntry fails the test with tb.Fatalf when an entry matches every
+// predicate.
+func (r *Recorder) RequireNoEntry(tb testing.TB, preds ...Predicate) {
+	tb.Helper()
+	if matches := r.Filter(preds...); len(matches) > 0 {
+		tb.Fatalf("pslogtest: unexpected entries match %s:\n%s", describe(preds), dump(matches))
+	}
+}
+
+// NoErrors reports every entry at ErrorLevel or above (Error, Fatal, Panic)
+// with tb.Errorf, together with lines the Recorder could not parse.
+func (r *Recorder) NoErrors(tb testing.TB) {
+	tb.Helper()
+	r.state.mu.Lock()
+	errs := append([]error(nil), r.state.errs...)
+	r.state.mu.Unlock()
+	for _, err := range errs {
+		tb.Errorf("pslogtest: %v", err)
+	}
+	for _, entry := range r.Entries() {
+		if entry.Level >= pslog.ErrorLevel && entry.Level < pslog.NoLevel {
+			tb.Errorf("pslogtest: unexpected %s entry: %s", pslog.LevelString(entry.Level), entry.Line)
+		}
+	}
+}
+
+func dump(entries []Entry) string {
+	if len(entries) == 0 {
+		return "  (none)"
+	}
+	var b strings.Builder
+	for i, entry := range entries {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("  ")
+		b.WriteString(entry.Line)
+	}
+	return b.String()
+}
+
+// parseEntry decodes line, taking the first levelKey and msgKey members as the
+// entry's level and message.
+func parseEntry(line, levelKey, msgKey string) (Entry, error) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return Entry{}, fmt.Errorf("parse %q: %w", line, err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return Entry{}, fmt.Errorf("parse %q: not a JSON object", line)
+	}
+	fields, err := decodeObject(dec)
+	if err != nil {
+		return Entry{}, fmt.Errorf("parse %q: %w", line, err)
+	}
+	entry := Entry{Level: pslog.NoLevel, Line: line, Fields: make(Fields, 0, len(fields))}
+	seenLevel, seenMsg := false, false
+	for _, f := range fields {
+		switch {
+		case f.Key == levelKey && !seenLevel:
+			seenLevel = true
+			if s, ok := f.Value.(string); ok {
+				if level, ok := pslog.ParseLevel(s); ok {
+					entry.Level = level
+					continue
+				}
+			}
+		case f.Key == msgKey && !seenMsg:
+			seenMsg = true
+			if s, ok := f.Value.(string); ok {
+				entry.Message = s
+				continue
+			}
+		}
+		entry.Fields = append(entry.Fields, f)
+	}
+	return entry, nil
+}
+
+// decodeObject reads the members of an object whose opening brace has been
+// consumed.
+func decodeObject(dec *json.Decoder) (Fields, error) {
+	var fields Fields
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, errors.New("object key is not a string")
+		}
+		value, err := decodeValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field{Key: key, Value: value})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		return decodeObject(dec)
+	case '[':
+		values := []any{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
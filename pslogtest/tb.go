@@ -0,0 +1,54 @@
+package pslogtest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+// tbWriter forwards complete lines to tb.Log.
+type tbWriter struct {
+	tb      testing.TB
+	mu      sync.Mutex
+	partial []byte
+}
+
+// TBWriter returns a writer that passes every line written to it to tb.Log.
+// Like tb.Log itself it must not be used after the test has finished.
+func TBWriter(tb testing.TB) io.Writer {
+	return &tbWriter{tb: tb}
+}
+
+func (w *tbWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		idx := bytes.IndexByte(w.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		w.tb.Log(string(w.partial[:idx]))
+		w.partial = w.partial[idx+1:]
+	}
+	return len(p), nil
+}
+
+// NewTB returns a console logger without colour or timestamps that writes to
+// tb.Log at TraceLevel. Passing opts replaces those defaults, except for
+// Fatal.Exit: Fatal marks the test as failed with tb.Errorf and returns
+// instead of exiting.
+func NewTB(tb testing.TB, opts ...pslog.Options) pslog.Logger {
+	options := pslog.Options{Mode: pslog.ModeConsole, NoColor: true, DisableTimestamp: true, MinLevel: pslog.TraceLevel}
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	options.Fatal.Exit = func(code int) {
+		tb.Errorf("pslogtest: Fatal called (exit code %d)", code)
+	}
+	return pslog.NewWithOptions(context.Background(), TBWriter(tb), options)
+}
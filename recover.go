@@ -0,0 +1,208 @@
+package pslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"strings"
+)
+
+const defaultPanicMessage = "panic recovered"
+
+// RecoverOption customizes Recover and Go.
+type RecoverOption func(*recoverConfig)
+
+type recoverConfig struct {
+	message string
+	keyvals []any
+	repanic bool
+}
+
+// WithPanicMessage sets the message of the entry logged for a recovered
+// panic. Defaults to "panic recovered".
+func WithPanicMessage(msg string) RecoverOption {
+	return func(cfg *recoverConfig) {
+		cfg.message = msg
+	}
+}
+
+// WithPanicKeyvals adds key/value pairs to the entry logged for a recovered
+// panic.
+func WithPanicKeyvals(keyvals ...any) RecoverOption {
+	return func(cfg *recoverConfig) {
+		cfg.keyvals = append(cfg.keyvals, keyvals...)
+	}
+}
+
+// WithRepanic makes Recover panic again with the original value once the
+// entry has been written, so the process still crashes.
+func WithRepanic() RecoverOption {
+	return func(cfg *recoverConfig) {
+		cfg.repanic = true
+	}
+}
+
+// Recover logs a panic in progress at PanicLevel and stops it. It must be
+// deferred directly:
+//
+//	defer pslog.Recover(logger)
+//
+// The entry carries the panic value under `panic`, the panicking goroutine's
+// stack (a `stack` array in structured mode, an indented block in console
+// mode), the logger's fields and any WithPanicKeyvals pairs. With WithRepanic
+// the panic continues after the entry has been written and any AsyncWriter
+// flushed.
+func Recover(logger Logger, opts ...RecoverOption) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	logPanic(logger, value, opts)
+}
+
+// Go runs fn in a new goroutine that recovers and logs panics like Recover.
+func Go(logger Logger, fn func(), opts ...RecoverOption) {
+	go func() {
+		defer Recover(logger, opts...)
+		fn()
+	}()
+}
+
+func logPanic(logger Logger, value any, opts []RecoverOption) {
+	cfg := recoverConfig{message: defaultPanicMessage}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	if logger == nil {
+		logger = noopLogger{}
+	}
+	frames := panicStack(captureStack())
+	keyvals := make([]any, 0, len(cfg.keyvals)+4)
+	keyvals = append(keyvals, "panic", value)
+	keyvals = append(keyvals, cfg.keyvals...)
+	if derived, ok := withPanicStack(logger, frames); ok {
+		logger = derived
+	} else {
+		keyvals = append(keyvals, stackKey, stackFrames(frames))
+	}
+	logger.Log(PanicLevel, cfg.message, keyvals...)
+	if !cfg.repanic {
+		return
+	}
+	_ = Flush(context.Background(), logger)
+	panic(value)
+}
+
+// withPanicStack derives a logger that attaches frames the way each emitter
+// renders stacks. It reports false for loggers pslog does not render itself.
+func withPanicStack(logger Logger, frames []string) (Logger, bool) {
+	switch l := logger.(type) {
+	case *multiLogger:
+		return l.each(func(inner Logger) Logger {
+			derived, _ := withPanicStack(inner, frames)
+			return derived
+		}), true
+	case coreLogger:
+		console := isConsoleLogger(logger)
+		return l.withCoreConfig(func(c *coreConfig) {
+			c.hooks = c.hooks.with(func(e *Entry) bool {
+				attachStack(e, frames, console)
+				return true
+			})
+		}), true
+	}
+	return logger, false
+}
+
+// panicStack drops the runtime frames above the panicking function.
+func panicStack(frames []string) []string {
+	for i, frame := range frames {
+		if strings.HasPrefix(frame, "runtime.gopanic ") {
+			frames = frames[i+1:]
+			break
+		}
+	}
+	for len(frames) > 0 && strings.HasPrefix(frames[0], "runtime.") {
+		frames = frames[1:]
+	}
+	return frames
+}
+
+func isConsoleLogger(logger Logger) bool {
+	switch logger.(type) {
+	case *consolePlainLogger, *consoleColorLogger:
+		return true
+	}
+	return false
+}
+
+// SetCrashOutput routes fatal runtime crash reports (unrecovered panics,
+// concurrent map writes, ...) to the file the logger writes to, via
+// runtime/debug.SetCrashOutput, so they land next to the log entries. It
+// looks through the writers pslog wraps (LOG_OUTPUT files and tees,
+// AsyncWriter, ObservedWriter) for an *os.File and returns an error when the
+// logger does not write to one.
+func SetCrashOutput(logger Logger, opts debug.CrashOptions) error {
+	var writers []io.Writer
+	switch l := logger.(type) {
+	case *multiLogger:
+		for _, inner := range l.loggers {
+			if cl, ok := inner.(coreLogger); ok {
+				writers = append(writers, cl.core().cfg.writer)
+			}
+		}
+	case coreLogger:
+		writers = append(writers, l.core().cfg.writer)
+	default:
+		return fmt.Errorf("set crash output: logger %T has no file output", logger)
+	}
+	file := outputFile(newTeeWriter(writers...))
+	if file == nil {
+		return errors.New("set crash output: logger does not write to a file")
+	}
+	return debug.SetCrashOutput(file, opts)
+}
+
+// outputFile returns the file behind w, preferring a file over the standard
+// streams when w tees to both.
+func outputFile(w io.Writer) *os.File {
+	var std *os.File
+	var walk func(io.Writer) *os.File
+	walk = func(w io.Writer) *os.File {
+		switch v := w.(type) {
+		case *os.File:
+			if v == os.Stdout || v == os.Stderr {
+				if std == nil {
+					std = v
+				}
+				return nil
+			}
+			return v
+		case *ownedOutput:
+			if f, ok := v.closer.(*os.File); ok {
+				return walk(f)
+			}
+			return walk(v.writer)
+		case *teeWriter:
+			for _, inner := range v.writers {
+				if f := walk(inner); f != nil {
+					return f
+				}
+			}
+		case *AsyncWriter:
+			return walk(v.dst)
+		case *ObservedWriter:
+			return walk(v.dst)
+		}
+		return nil
+	}
+	if f := walk(w); f != nil {
+		return f
+	}
+	return std
+}
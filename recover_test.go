@@ -0,0 +1,115 @@
+package pslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+//go:noinline
+func panicker() {
+	panic("kaboom")
+}
+
+func recoverInto(logger pslog.Logger, opts ...pslog.RecoverOption) {
+	defer pslog.Recover(logger, opts...)
+	panicker()
+}
+
+func TestRecoverLogsPanicWithStack(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true}).With("svc", "api")
+	recoverInto(logger, pslog.WithPanicKeyvals("job", 7))
+
+	var entry struct {
+		Level string   `json:"lvl"`
+		Msg   string   `json:"msg"`
+		Svc   string   `json:"svc"`
+		Panic string   `json:"panic"`
+		Job   int      `json:"job"`
+		Stack []string `json:"stack"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if entry.Level != "panic" || entry.Msg != "panic recovered" || entry.Svc != "api" || entry.Panic != "kaboom" || entry.Job != 7 {
+		t.Fatalf("unexpected entry: %s", buf.String())
+	}
+	if len(entry.Stack) == 0 || !strings.HasPrefix(entry.Stack[0], "pkt.systems/pslog_test.panicker ") {
+		t.Fatalf("expected the panicking function first, got %q", entry.Stack)
+	}
+}
+
+func TestRecoverConsoleStackBlock(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeConsole, DisableTimestamp: true, NoColor: true})
+	recoverInto(logger, pslog.WithPanicMessage("worker crashed"))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < 2 || lines[0] != "PNC worker crashed panic=kaboom" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "    pkt.systems/pslog_test.panicker ") {
+		t.Fatalf("expected indented stack, got %q", lines[1])
+	}
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true})
+	defer func() {
+		if r := recover(); r != "kaboom" {
+			t.Fatalf("expected the original panic value, got %v", r)
+		}
+		if !strings.Contains(buf.String(), `"panic":"kaboom"`) {
+			t.Fatalf("expected the entry before re-panicking, got %q", buf.String())
+		}
+	}()
+	recoverInto(logger, pslog.WithRepanic())
+	t.Fatal("expected a re-panic")
+}
+
+type signalWriter struct {
+	buf  bytes.Buffer
+	done chan struct{}
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	n, err := w.buf.Write(p)
+	close(w.done)
+	return n, err
+}
+
+func TestGoRecoversPanics(t *testing.T) {
+	w := &signalWriter{done: make(chan struct{})}
+	logger := pslog.NewWithOptions(nil, w, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true})
+	pslog.Go(logger, panicker)
+	<-w.done
+	if !strings.Contains(w.buf.String(), `"lvl":"panic","msg":"panic recovered","panic":"kaboom"`) {
+		t.Fatalf("expected a panic entry, got %q", w.buf.String())
+	}
+}
+
+func TestSetCrashOutput(t *testing.T) {
+	var buf bytes.Buffer
+	if err := pslog.SetCrashOutput(pslog.NewStructured(nil, &buf), debug.CrashOptions{}); err == nil {
+		t.Fatal("expected an error for a logger without file output")
+	}
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	logger := pslog.NewWithOptions(nil, file, pslog.Options{Mode: pslog.ModeStructured})
+	if err := pslog.SetCrashOutput(logger, debug.CrashOptions{}); err != nil {
+		t.Fatalf("SetCrashOutput: %v", err)
+	}
+	t.Cleanup(func() { _ = debug.SetCrashOutput(nil, debug.CrashOptions{}) })
+}
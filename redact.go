@@ -0,0 +1,245 @@
+package pslog
+
+import (
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const defaultRedactReplacement = "[REDACTED]"
+
+var (
+	// JWTPattern matches JSON Web Tokens for use in Redaction.Values.
+	JWTPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	// CardNumberPattern matches 13 to 19 digit payment card numbers, optionally
+	// grouped by spaces or dashes, for use in Redaction.Values.
+	CardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// Secret wraps a sensitive string so it is never rendered. Every encoder,
+// fmt and log/slog see the fixed text "[REDACTED]" instead of the value.
+type Secret string
+
+// String implements fmt.Stringer.
+func (Secret) String() string { return defaultRedactReplacement }
+
+// MarshalJSON implements json.Marshaler.
+func (Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + defaultRedactReplacement + `"`), nil
+}
+
+// LogValue implements slog.LogValuer.
+func (Secret) LogValue() slog.Value { return slog.StringValue(defaultRedactReplacement) }
+
+// Redaction masks sensitive data before it is encoded. Static fields added
+// via With are masked once when the logger's payload is built; runtime
+// keyvals are masked on every entry, after any Options.Hooks have run.
+type Redaction struct {
+	// Keys lists field names whose values are replaced entirely. Entries may
+	// be path.Match globs such as "*_token"; matching is case-insensitive.
+	Keys []string
+	// Values replaces the parts of values that match any expression, e.g.
+	// JWTPattern or CardNumberPattern. Strings, []byte, errors, numbers,
+	// booleans, times and fmt.Stringers are scanned in the form they are
+	// rendered in; a non-string value that matches is logged as the redacted
+	// string. Maps, slices, structs and ObjectMarshaler or ArrayMarshaler
+	// values are not scanned; wrap their sensitive parts in Secret instead.
+	Values []*regexp.Regexp
+	// Replacement is the text substituted for redacted data. Defaults to
+	// "[REDACTED]".
+	Replacement string
+}
+
+type redactor struct {
+	exact       map[string]struct{}
+	globs       []string
+	values      []*regexp.Regexp
+	replacement string
+	masked      any
+}
+
+func newRedactor(opts *Redaction) *redactor {
+	if opts == nil || (len(opts.Keys) == 0 && len(opts.Values) == 0) {
+		return nil
+	}
+	r := &redactor{replacement: opts.Replacement}
+	if r.replacement == "" {
+		r.replacement = defaultRedactReplacement
+	}
+	r.masked = r.replacement
+	for _, key := range opts.Keys {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		if strings.ContainsAny(key, "*?[") {
+			r.globs = append(r.globs, key)
+			continue
+		}
+		if r.exact == nil {
+			r.exact = make(map[string]struct{})
+		}
+		r.exact[key] = struct{}{}
+	}
+	for _, re := range opts.Values {
+		if re != nil {
+			r.values = append(r.values, re)
+		}
+	}
+	return r
+}
+
+func (r *redactor) matchKey(key string) bool {
+	if len(r.exact) == 0 && len(r.globs) == 0 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; 'A' <= c && c <= 'Z' {
+			key = strings.ToLower(key)
+			break
+		}
+	}
+	if _, ok := r.exact[key]; ok {
+		return true
+	}
+	for _, glob := range r.globs {
+		if ok, _ := path.Match(glob, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns v with the value patterns applied and whether it changed.
+// Groups are redacted recursively.
+func (r *redactor) value(v any) (any, bool) {
+	switch val := v.(type) {
+	case string:
+		return r.replaceString(val)
+	case TrustedString:
+		return r.replaceString(string(val))
+	case []byte:
+		return r.replaceString(string(val))
+	case keyvalGroup:
+		if redacted, ok := r.keyvals(val); ok {
+			return keyvalGroup(redacted), true
+		}
+	case error:
+		if redacted, ok := r.replaceString(val.Error()); ok {
+			return errorMessage(redacted.(string)), true
+		}
+	case deferred:
+		// Evaluate once here so the patterns see the real value and the
+		// encoder does not run the function again.
+		resolved := resolveValue(val)
+		if redacted, ok := r.value(resolved); ok {
+			return redacted, true
+		}
+		return resolved, true
+	case ObjectMarshaler, ArrayMarshaler:
+		return v, false
+	default:
+		if len(r.values) == 0 {
+			return v, false
+		}
+		if rendered, ok := keyFromAny(v); ok {
+			return r.replaceString(rendered)
+		}
+	}
+	return v, false
+}
+
+func (r *redactor) replaceString(s string) (any, bool) {
+	changed := false
+	for _, re := range r.values {
+		if re.MatchString(s) {
+			s = re.ReplaceAllLiteralString(s, r.replacement)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil, false
+	}
+	return s, true
+}
+
+// pair checks the key/value pair starting at i. A trailing value without a
+// key is only checked against the value patterns.
+func (r *redactor) pair(keyvals []any, i int) (int, any, bool) {
+	if i+1 >= len(keyvals) {
+		replacement, matched := r.value(keyvals[i])
+		return i, replacement, matched
+	}
+	if key, ok := keyvalKey(keyvals[i]); ok && r.matchKey(key) {
+		return i + 1, r.masked, true
+	}
+	replacement, matched := r.value(keyvals[i+1])
+	return i + 1, replacement, matched
+}
+
+// keyvals returns a redacted copy of keyvals, or false when nothing matched.
+func (r *redactor) keyvals(keyvals []any) ([]any, bool) {
+	var out []any
+	for i := 0; i < len(keyvals); i += 2 {
+		vi, replacement, matched := r.pair(keyvals, i)
+		if !matched {
+			continue
+		}
+		if out == nil {
+			out = append([]any(nil), keyvals...)
+		}
+		out[vi] = replacement
+	}
+	return out, out != nil
+}
+
+// fields returns the static fields with the policy applied, sharing the
+// input when nothing needs masking.
+func (r *redactor) fields(fields []field) []field {
+	var out []field
+	for i, f := range fields {
+		var replacement any
+		matched := false
+		if r.matchKey(f.key) {
+			replacement, matched = r.masked, true
+		} else {
+			replacement, matched = r.value(f.value)
+		}
+		if !matched {
+			continue
+		}
+		if out == nil {
+			out = cloneFields(fields)
+		}
+		out[i].value = replacement
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// hook masks runtime keyvals. It runs as the last hook in the chain so fields
+// added by user hooks are covered too.
+func (r *redactor) hook(e *Entry) bool {
+	for i := 0; i < len(e.Keyvals); i += 2 {
+		vi, replacement, matched := r.pair(e.Keyvals, i)
+		if matched {
+			e.own()
+			e.Keyvals[vi] = replacement
+		}
+	}
+	return true
+}
+
+func keyvalKey(v any) (string, bool) {
+	switch k := v.(type) {
+	case string:
+		return k, true
+	case TrustedString:
+		return string(k), true
+	default:
+		return "", false
+	}
+}
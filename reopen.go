@@ -0,0 +1,230 @@
+package pslog
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultReopenCheckInterval is how often a ReopenFile compares its handle
+// with the file at its path.
+const defaultReopenCheckInterval = time.Second
+
+// ReopenOptions configures a ReopenFile.
+type ReopenOptions struct {
+	// Signals trigger a reopen. Nil selects SIGHUP on Unix systems; use
+	// NoSignals to rely on inode checks alone.
+	Signals []os.Signal
+
+	// NoSignals disables signal handling.
+	NoSignals bool
+
+	// CheckInterval is how often the path is compared with the open file, so
+	// a rename or removal without a signal is picked up. Defaults to one
+	// second; a negative value disables the check.
+	CheckInterval time.Duration
+
+	// FileMode is used when the file is created. Defaults to 0600.
+	FileMode os.FileMode
+}
+
+// ReopenFile is an io.WriteCloser for files rotated by an external tool such
+// as logrotate with `create`. It reopens path when one of the configured
+// signals arrives, when the file at path is no longer the one being written,
+// or when Reopen is called. The new handle is swapped in while no write is in
+// flight, so every line lands whole in either the old or the new file. It is
+// safe for concurrent use.
+type ReopenFile struct {
+	path string
+	mode os.FileMode
+
+	mu     sync.RWMutex
+	file   *os.File
+	info   os.FileInfo
+	closed bool
+
+	signals chan os.Signal
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewReopenFile opens path for appending and starts watching for rotation.
+func NewReopenFile(path string, opts ReopenOptions) (*ReopenFile, error) {
+	if path == "" {
+		return nil, errors.New("reopen file: empty path")
+	}
+	if opts.FileMode == 0 {
+		opts.FileMode = defaultOutputFileMode
+	}
+	r := &ReopenFile{
+		path: path,
+		mode: opts.FileMode,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	file, info, err := r.openFile()
+	if err != nil {
+		return nil, err
+	}
+	r.file, r.info = file, info
+	signals := opts.Signals
+	if signals == nil {
+		signals = defaultReopenSignals()
+	}
+	if !opts.NoSignals && len(signals) > 0 {
+		r.signals = make(chan os.Signal, 1)
+		signal.Notify(r.signals, signals...)
+	}
+	interval := opts.CheckInterval
+	if interval == 0 {
+		interval = defaultReopenCheckInterval
+	}
+	go r.watch(interval)
+	return r, nil
+}
+
+func (r *ReopenFile) openFile() (*os.File, os.FileInfo, error) {
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, r.mode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reopen file: open %q: %w", r.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, fmt.Errorf("reopen file: stat %q: %w", r.path, err)
+	}
+	return file, info, nil
+}
+
+// Write appends p to the current file. Writes run concurrently with each
+// other; a reopen waits for them to finish.
+func (r *ReopenFile) Write(p []byte) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	return r.file.Write(p)
+}
+
+// Reopen opens path again and swaps the new handle in. On failure the
+// current file stays in use.
+func (r *ReopenFile) Reopen() error {
+	file, info, err := r.openFile()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		_ = file.Close()
+		return os.ErrClosed
+	}
+	previous := r.file
+	r.file, r.info = file, info
+	r.mu.Unlock()
+	return previous.Close()
+}
+
+// Close stops watching and closes the file. It is safe to call more than
+// once.
+func (r *ReopenFile) Close() error {
+	var err error
+	r.once.Do(func() {
+		close(r.stop)
+		<-r.done
+		r.mu.Lock()
+		r.closed = true
+		err = r.file.Close()
+		r.mu.Unlock()
+	})
+	return err
+}
+
+func (r *ReopenFile) watch(interval time.Duration) {
+	defer close(r.done)
+	if r.signals != nil {
+		defer signal.Stop(r.signals)
+	}
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-r.signals:
+			_ = r.Reopen()
+		case <-tick:
+			if r.moved() {
+				_ = r.Reopen()
+			}
+		}
+	}
+}
+
+// moved reports whether path no longer names the open file.
+func (r *ReopenFile) moved() bool {
+	current, err := os.Stat(r.path)
+	if err != nil {
+		return true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !os.SameFile(current, r.info)
+}
+
+// parseReopenSpec parses the LOG_OUTPUT form
+// "/var/log/app.log?signal=hup&check=5s".
+func parseReopenSpec(spec string, mode os.FileMode) (string, ReopenOptions, error) {
+	path, query, _ := strings.Cut(spec, "?")
+	path = strings.TrimSpace(path)
+	opts := ReopenOptions{FileMode: mode}
+	if path == "" {
+		return "", opts, errors.New("missing path")
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", opts, err
+	}
+	for key, list := range values {
+		value := strings.ToLower(strings.TrimSpace(list[len(list)-1]))
+		switch strings.ToLower(key) {
+		case "signal":
+			if value == "none" || value == "off" {
+				opts.NoSignals = true
+				continue
+			}
+			opts.Signals = opts.Signals[:0]
+			for name := range strings.SplitSeq(value, ",") {
+				sig, ok := reopenSignalByName(strings.TrimSpace(name))
+				if !ok {
+					return "", opts, fmt.Errorf("signal: unsupported signal %q", name)
+				}
+				opts.Signals = append(opts.Signals, sig)
+			}
+		case "check", "interval":
+			if value == "off" || value == "none" {
+				opts.CheckInterval = -1
+				continue
+			}
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return "", opts, fmt.Errorf("check: invalid interval %q", value)
+			}
+			opts.CheckInterval = d
+		default:
+			return "", opts, fmt.Errorf("unknown parameter %q", key)
+		}
+	}
+	return path, opts, nil
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package pslog
+
+import "os"
+
+// Platforms without SIGHUP rely on inode checks and explicit Reopen calls.
+func defaultReopenSignals() []os.Signal {
+	return nil
+}
+
+func reopenSignalByName(string) (os.Signal, bool) {
+	return nil, false
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package pslog
+
+import (
+	"os"
+	"syscall"
+)
+
+func defaultReopenSignals() []os.Signal {
+	return []os.Signal{syscall.SIGHUP}
+}
+
+func reopenSignalByName(name string) (os.Signal, bool) {
+	switch name {
+	case "hup", "sighup":
+		return syscall.SIGHUP, true
+	case "usr1", "sigusr1":
+		return syscall.SIGUSR1, true
+	case "usr2", "sigusr2":
+		return syscall.SIGUSR2, true
+	}
+	return nil, false
+}
@@ -0,0 +1,145 @@
+package pslog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReopenFileFollowsRenamedPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	r, err := NewReopenFile(path, ReopenOptions{NoSignals: true, CheckInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer r.Close()
+	_, _ = r.Write([]byte("before\n"))
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	waitFor(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+	_, _ = r.Write([]byte("after\n"))
+
+	rotated, _ := os.ReadFile(path + ".1")
+	current, _ := os.ReadFile(path)
+	if string(rotated) != "before\n" || string(current) != "after\n" {
+		t.Fatalf("unexpected contents: rotated=%q current=%q", rotated, current)
+	}
+}
+
+func TestReopenFileKeepsLinesWholeAcrossReopens(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	r, err := NewReopenFile(path, ReopenOptions{NoSignals: true, CheckInterval: -1})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	const writers, lines = 8, 200
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range lines {
+				line := fmt.Sprintf("writer=%d line=%d %s\n", w, i, strings.Repeat("x", 64))
+				if _, err := r.Write([]byte(line)); err != nil {
+					t.Errorf("write: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	for i := range 5 {
+		if err := os.Rename(path, fmt.Sprintf("%s.%d", path, i)); err != nil {
+			t.Fatalf("rename: %v", err)
+		}
+		if err := r.Reopen(); err != nil {
+			t.Fatalf("reopen: %v", err)
+		}
+	}
+	wg.Wait()
+	if err := r.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "app.log*"))
+	total := 0
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		for line := range strings.SplitSeq(strings.TrimSuffix(string(data), "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			if !strings.HasPrefix(line, "writer=") || !strings.HasSuffix(line, strings.Repeat("x", 64)) {
+				t.Fatalf("interleaved line %q in %s", line, name)
+			}
+			total++
+		}
+	}
+	if total != writers*lines {
+		t.Fatalf("expected %d lines, found %d", writers*lines, total)
+	}
+}
+
+func TestParseReopenSpec(t *testing.T) {
+	path, opts, err := parseReopenSpec("/var/log/app.log?signal=none&check=5s", 0o640)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if path != "/var/log/app.log" || !opts.NoSignals || opts.CheckInterval != 5*time.Second || opts.FileMode != 0o640 {
+		t.Fatalf("unexpected result %q %+v", path, opts)
+	}
+	if _, opts, err := parseReopenSpec("/a.log?check=off", 0o600); err != nil || opts.CheckInterval >= 0 {
+		t.Fatalf("expected check=off to disable checks, got %+v %v", opts, err)
+	}
+	for _, spec := range []string{"", "/a.log?signal=bogus", "/a.log?check=-1s", "/a.log?size=1"} {
+		if _, _, err := parseReopenSpec(spec, 0o600); err == nil {
+			t.Fatalf("expected %q to fail", spec)
+		}
+	}
+}
+
+func TestLoggerFromEnvReopenOutputIsOwned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("LOG_OUTPUT", "reopen:"+path+"?signal=none")
+	t.Setenv("LOG_MODE", "json")
+	logger := LoggerFromEnv(context.Background())
+	logger.Info("hello")
+	output := logger.(coreLogger).core().cfg.writer.(*ownedOutput)
+	reopening, ok := output.closer.(*ReopenFile)
+	if !ok {
+		t.Fatalf("expected a ReopenFile, got %T", output.closer)
+	}
+	if err := Close(logger); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := reopening.Write([]byte("x\n")); err == nil {
+		t.Fatalf("expected the reopen file to be closed with the logger")
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), `"msg":"hello"`) {
+		t.Fatalf("unexpected content %q", data)
+	}
+}
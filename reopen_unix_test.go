@@ -0,0 +1,35 @@
+//go:build unix
+
+package pslog
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestReopenFileReopensOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	r, err := NewReopenFile(path, ReopenOptions{Signals: []os.Signal{syscall.SIGUSR1}, CheckInterval: -1})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer r.Close()
+	_, _ = r.Write([]byte("before\n"))
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+	waitFor(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+	_, _ = r.Write([]byte("after\n"))
+	if data, _ := os.ReadFile(path); string(data) != "after\n" {
+		t.Fatalf("expected writes to move to the new file, got %q", data)
+	}
+}
@@ -0,0 +1,548 @@
+package pslog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateSchedule selects time-based rotation for a RotatingFile.
+type RotateSchedule uint8
+
+const (
+	// RotateNever disables time-based rotation. This is the default.
+	RotateNever RotateSchedule = iota
+	// RotateHourly starts a new segment at the top of every hour.
+	RotateHourly
+	// RotateDaily starts a new segment at midnight.
+	RotateDaily
+)
+
+// segmentTimeLayout names segments after the time they were opened, so they
+// sort chronologically.
+const segmentTimeLayout = "20060102T150405.000"
+
+// RotateOptions configures a RotatingFile.
+type RotateOptions struct {
+	// MaxSize starts a new segment before a write would take the current one
+	// past MaxSize bytes. Zero disables size-based rotation.
+	MaxSize int64
+
+	// Schedule starts a new segment every hour or day.
+	Schedule RotateSchedule
+
+	// Keep is the number of rotated segments to retain. Zero keeps all.
+	Keep int
+
+	// MaxAge removes rotated segments last written longer ago than MaxAge.
+	// Zero keeps them regardless of age.
+	MaxAge time.Duration
+
+	// Compress gzips rotated segments in the background.
+	Compress bool
+
+	// FileMode is used for new segments. Defaults to 0600.
+	FileMode os.FileMode
+
+	// UTC names segments and aligns the schedule in UTC instead of local
+	// time.
+	UTC bool
+}
+
+// RotatingFile is an io.WriteCloser that splits output into segments next to
+// path. Each segment is named after the time it was opened, e.g.
+// app-20240102T150405.000.log for app.log, and path is kept as a symlink to
+// the segment being written so `tail -F path` follows rotations. On systems
+// without symlinks the link is skipped. Compression and pruning of rotated
+// segments run on a background goroutine, never inside Write. It is safe for
+// concurrent use; each Write lands in a single segment.
+type RotatingFile struct {
+	path string
+	dir  string
+	stem string
+	ext  string
+	opts RotateOptions
+	now  func() time.Time
+
+	mu      sync.Mutex
+	file    *os.File
+	segment string
+	size    int64
+	next    time.Time
+	closed  bool
+
+	work chan struct{}
+	done chan struct{}
+}
+
+// NewRotatingFile opens a RotatingFile for path. When path already links to a
+// segment of the current period that still has room, writing resumes there.
+// A regular file at path is moved aside as a rotated segment first.
+func NewRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	return newRotatingFile(path, opts, time.Now)
+}
+
+func newRotatingFile(path string, opts RotateOptions, now func() time.Time) (*RotatingFile, error) {
+	if path == "" {
+		return nil, errors.New("rotating file: empty path")
+	}
+	if opts.FileMode == 0 {
+		opts.FileMode = defaultOutputFileMode
+	}
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	r := &RotatingFile{
+		path: path,
+		dir:  filepath.Dir(path),
+		stem: strings.TrimSuffix(base, ext),
+		ext:  ext,
+		opts: opts,
+		now:  now,
+		work: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	go r.maintainLoop()
+	r.kick()
+	return r, nil
+}
+
+// Write appends p to the current segment, rotating first when p would exceed
+// MaxSize or the schedule has moved on.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if r.due(len(p)) {
+		// A failed rotation keeps the current segment; the next write retries.
+		_ = r.rotateLocked()
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate starts a new segment immediately.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return os.ErrClosed
+	}
+	return r.rotateLocked()
+}
+
+// Close closes the current segment and waits for background compression and
+// pruning to finish. It is safe to call more than once.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		<-r.done
+		return nil
+	}
+	r.closed = true
+	err := r.file.Close()
+	r.mu.Unlock()
+	close(r.work)
+	<-r.done
+	return err
+}
+
+func (r *RotatingFile) due(n int) bool {
+	if r.opts.MaxSize > 0 && r.size > 0 && r.size+int64(n) > r.opts.MaxSize {
+		return true
+	}
+	return !r.next.IsZero() && !r.now().Before(r.next)
+}
+
+func (r *RotatingFile) clock() time.Time {
+	now := r.now()
+	if r.opts.UTC {
+		return now.UTC()
+	}
+	return now.Local()
+}
+
+// open resumes the linked segment when it belongs to the current period and
+// has room, and starts a new one otherwise.
+func (r *RotatingFile) open() error {
+	now := r.clock()
+	if info, err := os.Lstat(r.path); err == nil {
+		if info.Mode()&os.ModeSymlink != 0 {
+			if r.resume(now) {
+				return nil
+			}
+		} else if info.Mode().IsRegular() {
+			aside, err := r.reserveSegmentName(info.ModTime())
+			if err != nil {
+				return err
+			}
+			_ = os.Remove(aside)
+			if err := os.Rename(r.path, aside); err != nil {
+				return fmt.Errorf("rotating file: move %q aside: %w", r.path, err)
+			}
+		}
+	}
+	return r.rotateLocked()
+}
+
+func (r *RotatingFile) resume(now time.Time) bool {
+	target, err := os.Readlink(r.path)
+	if err != nil {
+		return false
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(r.dir, target)
+	}
+	opened, ok := r.segmentTime(filepath.Base(target))
+	if !ok || (r.opts.Schedule != RotateNever && !r.periodStart(opened).Equal(r.periodStart(now))) {
+		return false
+	}
+	file, err := os.OpenFile(target, os.O_APPEND|os.O_WRONLY, r.opts.FileMode)
+	if err != nil {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil || (r.opts.MaxSize > 0 && info.Size() >= r.opts.MaxSize) {
+		_ = file.Close()
+		return false
+	}
+	r.file, r.segment, r.size = file, target, info.Size()
+	r.next = r.nextBoundary(now)
+	return true
+}
+
+// rotateLocked opens a new segment, points the link at it and closes the
+// previous one.
+func (r *RotatingFile) rotateLocked() error {
+	now := r.clock()
+	name, err := r.reserveSegmentName(now)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY|os.O_CREATE, r.opts.FileMode)
+	if err != nil {
+		_ = os.Remove(name)
+		return fmt.Errorf("rotating file: open %q: %w", name, err)
+	}
+	previous := r.file
+	r.file, r.segment, r.size = file, name, 0
+	r.next = r.nextBoundary(now)
+	r.link()
+	if previous != nil {
+		_ = previous.Close()
+		r.kick()
+	}
+	return nil
+}
+
+// reserveSegmentName creates an empty file for a segment opened at t, adding
+// a counter when a segment with the same timestamp already exists.
+func (r *RotatingFile) reserveSegmentName(t time.Time) (string, error) {
+	if r.opts.UTC {
+		t = t.UTC()
+	}
+	base := r.stem + "-" + t.Format(segmentTimeLayout)
+	for i := 0; ; i++ {
+		name := base
+		if i > 0 {
+			name += "-" + strconv.Itoa(i)
+		}
+		name = filepath.Join(r.dir, name+r.ext)
+		file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, r.opts.FileMode)
+		if err == nil {
+			return name, file.Close()
+		}
+		if !errors.Is(err, os.ErrExist) || i >= 1000 {
+			return "", fmt.Errorf("rotating file: create segment: %w", err)
+		}
+	}
+}
+
+// link atomically points path at the current segment. Errors are ignored so
+// platforms without symlinks keep rotating.
+func (r *RotatingFile) link() {
+	tmp := r.path + ".link"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(r.segment), tmp); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
+
+func (r *RotatingFile) periodStart(t time.Time) time.Time {
+	switch r.opts.Schedule {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+func (r *RotatingFile) nextBoundary(t time.Time) time.Time {
+	start := r.periodStart(t)
+	switch r.opts.Schedule {
+	case RotateHourly:
+		return start.Add(time.Hour)
+	case RotateDaily:
+		return start.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// segmentTime parses the opening time out of a segment file name.
+func (r *RotatingFile) segmentTime(name string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(name, r.stem+"-")
+	if !ok || len(rest) < len(segmentTimeLayout) {
+		return time.Time{}, false
+	}
+	suffix := strings.TrimSuffix(rest[len(segmentTimeLayout):], ".gz")
+	if suffix != r.ext {
+		counter, ok := strings.CutSuffix(suffix, r.ext)
+		if !ok || !strings.HasPrefix(counter, "-") {
+			return time.Time{}, false
+		}
+		if _, err := strconv.Atoi(counter[1:]); err != nil {
+			return time.Time{}, false
+		}
+	}
+	loc := time.Local
+	if r.opts.UTC {
+		loc = time.UTC
+	}
+	t, err := time.ParseInLocation(segmentTimeLayout, rest[:len(segmentTimeLayout)], loc)
+	return t, err == nil
+}
+
+func (r *RotatingFile) kick() {
+	select {
+	case r.work <- struct{}{}:
+	default:
+	}
+}
+
+func (r *RotatingFile) maintainLoop() {
+	defer close(r.done)
+	for range r.work {
+		r.maintain()
+	}
+}
+
+type rotatedSegment struct {
+	path    string
+	modTime time.Time
+}
+
+// maintain compresses and prunes the rotated segments.
+func (r *RotatingFile) maintain() {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return
+	}
+	var segments []rotatedSegment
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if _, ok := r.segmentTime(entry.Name()); !ok {
+			continue
+		}
+		path := filepath.Join(r.dir, entry.Name())
+		if r.isActive(path) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if r.opts.Compress && !strings.HasSuffix(path, ".gz") {
+			if compressed, err := compressSegment(path, info, r.opts.FileMode); err == nil {
+				path = compressed
+			}
+		}
+		segments = append(segments, rotatedSegment{path: path, modTime: info.ModTime()})
+	}
+	slices.SortFunc(segments, func(a, b rotatedSegment) int {
+		if c := b.modTime.Compare(a.modTime); c != 0 {
+			return c
+		}
+		return strings.Compare(b.path, a.path)
+	})
+	cutoff := time.Time{}
+	if r.opts.MaxAge > 0 {
+		cutoff = r.now().Add(-r.opts.MaxAge)
+	}
+	for i, segment := range segments {
+		if (r.opts.Keep > 0 && i >= r.opts.Keep) || (!cutoff.IsZero() && segment.modTime.Before(cutoff)) {
+			_ = os.Remove(segment.path)
+		}
+	}
+}
+
+// isActive reports whether path is the segment being written. A rotation can
+// run while maintain works through the directory, so each candidate is
+// checked under the lock; a segment that has been rotated out never becomes
+// active again.
+func (r *RotatingFile) isActive(path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return path == r.segment
+}
+
+// compressSegment gzips path into path.gz, keeping its modification time, and
+// removes the original.
+func compressSegment(path string, info os.FileInfo, mode os.FileMode) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	target := path + ".gz"
+	tmp := target + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return "", err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(tmp, target)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	_ = src.Close()
+	_ = os.Remove(path)
+	return target, nil
+}
+
+// parseRotateSpec parses the LOG_OUTPUT form
+// "/var/log/app.log?max=100MB&keep=7&age=7d&every=daily&compress=true&utc=true".
+func parseRotateSpec(spec string, mode os.FileMode) (string, RotateOptions, error) {
+	path, query, _ := strings.Cut(spec, "?")
+	path = strings.TrimSpace(path)
+	opts := RotateOptions{FileMode: mode}
+	if path == "" {
+		return "", opts, errors.New("missing path")
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", opts, err
+	}
+	for key, list := range values {
+		value := strings.TrimSpace(list[len(list)-1])
+		switch strings.ToLower(key) {
+		case "max", "size", "maxsize":
+			size, err := parseByteSize(value)
+			if err != nil {
+				return "", opts, fmt.Errorf("max: %w", err)
+			}
+			opts.MaxSize = size
+		case "keep":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return "", opts, fmt.Errorf("keep: invalid count %q", value)
+			}
+			opts.Keep = n
+		case "age", "maxage":
+			age, err := parseRetentionAge(value)
+			if err != nil {
+				return "", opts, fmt.Errorf("age: %w", err)
+			}
+			opts.MaxAge = age
+		case "every", "schedule":
+			switch strings.ToLower(value) {
+			case "", "never", "none":
+				opts.Schedule = RotateNever
+			case "hourly", "hour", "1h":
+				opts.Schedule = RotateHourly
+			case "daily", "day", "24h":
+				opts.Schedule = RotateDaily
+			default:
+				return "", opts, fmt.Errorf("every: unknown schedule %q", value)
+			}
+		case "compress", "gzip":
+			enabled, ok := parseEnvBool(value)
+			if !ok {
+				return "", opts, fmt.Errorf("compress: invalid boolean %q", value)
+			}
+			opts.Compress = enabled
+		case "utc":
+			enabled, ok := parseEnvBool(value)
+			if !ok {
+				return "", opts, fmt.Errorf("utc: invalid boolean %q", value)
+			}
+			opts.UTC = enabled
+		default:
+			return "", opts, fmt.Errorf("unknown parameter %q", key)
+		}
+	}
+	return path, opts, nil
+}
+
+// parseByteSize accepts plain byte counts and K, M and G suffixes (optionally
+// followed by B or iB), all powers of 1024.
+func parseByteSize(value string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(value))
+	upper = strings.TrimSuffix(strings.TrimSuffix(upper, "B"), "I")
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(upper, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(upper, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(upper, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		upper = upper[:len(upper)-1]
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(upper), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return n * multiplier, nil
+}
+
+// parseRetentionAge accepts time.ParseDuration values plus whole days ("7d").
+func parseRetentionAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", value)
+	}
+	return d, nil
+}
@@ -0,0 +1,307 @@
+package pslog
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func segmentNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "app-") {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestRotatingFileRotatesBySizeAndKeepsLink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	clock := &fakeClock{now: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)}
+	r, err := newRotatingFile(path, RotateOptions{MaxSize: 10, UTC: true}, clock.Now)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, line := range []string{"line-one\n", "line-two\n", "line-3\n"} {
+		clock.Advance(time.Second)
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read through link: %v", err)
+		}
+		if string(data) != "line-3\n" {
+			t.Fatalf("expected link to point at the current segment, got %q", data)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	names := segmentNames(t, dir)
+	want := []string{"app-20240102T150405.000.log", "app-20240102T150407.000.log", "app-20240102T150408.000.log"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("unexpected segments %q", names)
+	}
+	if _, err := r.Write([]byte("late\n")); err == nil {
+		t.Fatalf("expected write after close to fail")
+	}
+}
+
+func TestRotatingFileScheduleKeepAndCompress(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	clock := &fakeClock{now: time.Date(2024, 1, 2, 23, 0, 0, 0, time.UTC)}
+	r, err := newRotatingFile(path, RotateOptions{Schedule: RotateHourly, Keep: 2, Compress: true, UTC: true}, clock.Now)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for i := range 4 {
+		if _, err := r.Write([]byte("hour " + string(rune('a'+i)) + "\n")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		clock.Advance(time.Hour)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	names := segmentNames(t, dir)
+	want := []string{"app-20240103T000000.000.log.gz", "app-20240103T010000.000.log.gz", "app-20240103T020000.000.log"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("unexpected segments %q", names)
+	}
+	file, err := os.Open(filepath.Join(dir, names[1]))
+	if err != nil {
+		t.Fatalf("open gz: %v", err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil || string(data) != "hour c\n" {
+		t.Fatalf("unexpected compressed content %q (%v)", data, err)
+	}
+}
+
+func TestRotatingFileRotatesConcurrentlyWithMaintenance(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRotatingFile(filepath.Join(dir, "app.log"), RotateOptions{Compress: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// Stop the background loop and run maintenance back to back instead, so
+	// rotations keep landing in the middle of it.
+	close(r.work)
+	<-r.done
+	r.work = make(chan struct{}, 1)
+	stop := make(chan struct{})
+	maintained := make(chan struct{})
+	go func() {
+		defer close(maintained)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				r.maintain()
+			}
+		}
+	}()
+	// Write continuously while rotating, so each segment is live for a while.
+	var written atomic.Int64
+	writing := make(chan struct{})
+	go func() {
+		defer close(writing)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if _, err := r.Write([]byte("line\n")); err != nil {
+				t.Errorf("write: %v", err)
+				return
+			}
+			written.Add(1)
+		}
+	}()
+	for range 20 {
+		time.Sleep(100 * time.Microsecond)
+		if err := r.Rotate(); err != nil {
+			t.Fatalf("rotate: %v", err)
+		}
+	}
+	close(stop)
+	<-writing
+	<-maintained
+	if err := r.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	lines := 0
+	for _, name := range segmentNames(t, dir) {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("open %s: %v", name, err)
+		}
+		var src io.Reader = file
+		if strings.HasSuffix(name, ".gz") {
+			zr, err := gzip.NewReader(file)
+			if err != nil {
+				t.Fatalf("gzip %s: %v", name, err)
+			}
+			src = zr
+		}
+		data, err := io.ReadAll(src)
+		_ = file.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		lines += strings.Count(string(data), "\n")
+	}
+	if int64(lines) != written.Load() {
+		t.Fatalf("expected %d lines across segments, got %d", written.Load(), lines)
+	}
+}
+
+func TestRotatingFilePrunesByAge(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "app-20230101T000000.000.log")
+	unrelated := filepath.Join(dir, "app-errors.log")
+	for _, name := range []string{old, unrelated} {
+		if err := os.WriteFile(name, []byte("x\n"), 0o600); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+		stale := time.Now().Add(-48 * time.Hour)
+		if err := os.Chtimes(name, stale, stale); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+	r, err := NewRotatingFile(filepath.Join(dir, "app.log"), RotateOptions{MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("expected stale segment to be removed, got %v", err)
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Fatalf("unrelated file must be kept: %v", err)
+	}
+}
+
+func TestRotatingFileResumesLinkedSegment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("legacy\n"), 0o600); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	r, err := NewRotatingFile(path, RotateOptions{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_, _ = r.Write([]byte("first\n"))
+	_ = r.Close()
+	r, err = NewRotatingFile(path, RotateOptions{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	_, _ = r.Write([]byte("second\n"))
+	_ = r.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "first\nsecond\n" {
+		t.Fatalf("expected writes to resume the linked segment, got %q (%v)", data, err)
+	}
+	if names := segmentNames(t, dir); len(names) != 2 {
+		t.Fatalf("expected the legacy file moved aside plus one segment, got %q", names)
+	}
+}
+
+func TestParseRotateSpec(t *testing.T) {
+	path, opts, err := parseRotateSpec("/var/log/app.log?max=100MB&keep=7&age=7d&every=daily&compress=true&utc=1", 0o640)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := RotateOptions{MaxSize: 100 << 20, Keep: 7, MaxAge: 7 * 24 * time.Hour, Schedule: RotateDaily, Compress: true, UTC: true, FileMode: 0o640}
+	if path != "/var/log/app.log" || opts != want {
+		t.Fatalf("unexpected result %q %+v", path, opts)
+	}
+	for _, spec := range []string{"", "?max=1", "/a.log?max=lots", "/a.log?keep=-1", "/a.log?every=weekly", "/a.log?colour=1"} {
+		if _, _, err := parseRotateSpec(spec, 0o600); err == nil {
+			t.Fatalf("expected %q to fail", spec)
+		}
+	}
+	for value, want := range map[string]int64{"512": 512, "4k": 4 << 10, "2MiB": 2 << 20, "1G": 1 << 30} {
+		if got, err := parseByteSize(value); err != nil || got != want {
+			t.Fatalf("parseByteSize(%q) = %d, %v", value, got, err)
+		}
+	}
+}
+
+func TestLoggerFromEnvRotateOutputIsOwned(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LOG_OUTPUT", "rotate:"+filepath.Join(dir, "app.log")+"?max=64&keep=1")
+	t.Setenv("LOG_MODE", "json")
+	t.Setenv("LOG_DISABLE_TIMESTAMP", "true")
+	logger := LoggerFromEnv(context.Background())
+	for range 4 {
+		logger.Info("a line that is long enough to rotate")
+	}
+	output, ok := logger.(coreLogger).core().cfg.writer.(*ownedOutput)
+	if !ok {
+		t.Fatalf("expected an owned output, got %T", logger.(coreLogger).core().cfg.writer)
+	}
+	rotating := output.closer.(*RotatingFile)
+	if err := Close(logger); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := rotating.Write([]byte("x\n")); err == nil {
+		t.Fatalf("expected the rotating file to be closed with the logger")
+	}
+	if names := segmentNames(t, dir); len(names) != 2 {
+		t.Fatalf("expected the current segment plus one kept, got %q", names)
+	}
+}
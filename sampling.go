@@ -0,0 +1,208 @@
+package pslog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultSamplingInterval        = time.Second
+	defaultSamplingFirst           = 100
+	defaultSamplingSummaryInterval = 10 * time.Second
+	samplerBuckets                 = 4096
+)
+
+// Sampling thins out repetitive log entries. Entries are keyed by level and
+// message: within every Interval the first First entries of a key are
+// emitted, after which only every Thereafter-th entry is. Error, Fatal and
+// Panic entries are never sampled.
+//
+// Suppressed entries are counted, and a background ticker reports them as a
+// `logger.sampling.suppressed` entry every SummaryInterval in which something
+// was suppressed. The ticker belongs to the logger the options were passed
+// to: Close on that logger, or cancelling its context, stops it after a final
+// summary.
+type Sampling struct {
+	// Interval is the window each key is counted in. Defaults to one second.
+	Interval time.Duration
+	// First is the number of entries per key and window emitted before
+	// sampling starts. Zero selects the default of 100; a negative value
+	// emits none, so only every Thereafter-th entry is written.
+	First int
+	// Thereafter emits every Thereafter-th entry once First has been reached.
+	// Zero suppresses the rest of the window.
+	Thereafter int
+	// SummaryInterval is the period of the suppression summary. Defaults to
+	// ten seconds; a negative value disables the summary and its ticker.
+	SummaryInterval time.Duration
+}
+
+// samplerCountBits splits a counter's state into the window number in the
+// high bits and the count within that window in the low bits.
+const (
+	samplerCountBits = 40
+	samplerCountMask = 1<<samplerCountBits - 1
+)
+
+// samplerCounter packs a window number and a count into one word, so moving
+// to a new window and restarting the count is a single atomic step.
+type samplerCounter struct {
+	state atomic.Uint64
+}
+
+// sampler is shared by every logger derived from the adapter that created it.
+// Keys hash into a fixed table of counters, so distinct messages that collide
+// share a budget; this keeps the hot path lock- and allocation-free.
+type sampler struct {
+	start      int64
+	interval   int64
+	first      uint64
+	thereafter uint64
+	summary    int64
+	counters   [samplerBuckets]samplerCounter
+	suppressed atomic.Uint64
+	reporter   Logger
+	owner      atomic.Uintptr
+
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func newSampler(opts *Sampling) *sampler {
+	if opts == nil {
+		return nil
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultSamplingInterval
+	}
+	first := opts.First
+	if first == 0 {
+		first = defaultSamplingFirst
+	}
+	first = max(first, 0)
+	thereafter := max(opts.Thereafter, 0)
+	summary := opts.SummaryInterval
+	if summary == 0 {
+		summary = defaultSamplingSummaryInterval
+	}
+	s := &sampler{
+		start:      time.Now().UnixNano(),
+		interval:   int64(interval),
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		summary:    int64(summary),
+	}
+	if s.summary > 0 {
+		s.stopCh = make(chan struct{})
+		s.doneCh = make(chan struct{})
+	}
+	return s
+}
+
+// attachReporter derives the logger used for suppression summaries and
+// starts the summary ticker. The reporter skips sampling and level filtering
+// so the summary is always written.
+func (s *sampler) attachReporter(logger Logger) {
+	if s == nil || s.stopCh == nil {
+		return
+	}
+	cl, ok := logger.(coreLogger)
+	if !ok {
+		close(s.doneCh)
+		return
+	}
+	s.reporter = cl.withCoreConfig(func(cfg *coreConfig) {
+		cfg.sampler = nil
+		cfg.levelVar = nil
+		cfg.forcedLevel = nil
+		cfg.minLevel = TraceLevel
+	})
+	go s.run()
+}
+
+// allow reports whether an entry at level with msg should be emitted.
+func (s *sampler) allow(level Level, msg string) bool {
+	if level >= ErrorLevel {
+		return true
+	}
+	window := uint64((time.Now().UnixNano() - s.start) / s.interval)
+	n := s.counters[samplerBucket(level, msg)].inc(window)
+	if n <= s.first || (s.thereafter > 0 && (n-s.first)%s.thereafter == 0) {
+		return true
+	}
+	s.suppressed.Add(1)
+	return false
+}
+
+// run writes a summary every SummaryInterval until stop is called, then
+// writes a last one for whatever was suppressed since.
+func (s *sampler) run() {
+	defer close(s.doneCh)
+	ticker := time.NewTicker(time.Duration(s.summary))
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-s.stopCh:
+			s.report(time.Since(last))
+			return
+		case now := <-ticker.C:
+			s.report(now.Sub(last))
+			last = now
+		}
+	}
+}
+
+func (s *sampler) report(window time.Duration) {
+	if n := s.suppressed.Swap(0); n > 0 {
+		s.reporter.Info("logger.sampling.suppressed", "suppressed", n, "window", window)
+	}
+}
+
+// claimOwnership records owner as the logger whose Close stops the ticker.
+// Loggers derived from it share the sampler but do not own it.
+func (s *sampler) claimOwnership(owner uintptr) {
+	if s == nil || owner == 0 {
+		return
+	}
+	s.owner.CompareAndSwap(0, owner)
+}
+
+// stop ends the summary ticker for owner and waits for the final summary.
+// It is a no-op for other loggers and safe to call more than once.
+func (s *sampler) stop(owner uintptr) {
+	if s == nil || s.stopCh == nil || s.owner.Load() != owner {
+		return
+	}
+	s.stopOnce.Do(func() { close(s.stopCh) })
+	<-s.doneCh
+}
+
+// inc counts an entry in window and returns the count so far. The first
+// entry of a new window swaps in the new window with a count of one; entries
+// that lose the race to it retry and count into that window.
+func (c *samplerCounter) inc(window uint64) uint64 {
+	window <<= samplerCountBits
+	for {
+		state := c.state.Load()
+		if state&^samplerCountMask == window {
+			return c.state.Add(1) & samplerCountMask
+		}
+		if c.state.CompareAndSwap(state, window|1) {
+			return 1
+		}
+	}
+}
+
+// samplerBucket hashes level and msg with FNV-1a.
+func samplerBucket(level Level, msg string) uint32 {
+	h := uint32(2166136261)
+	h = (h ^ uint32(uint8(level))) * 16777619
+	for i := 0; i < len(msg); i++ {
+		h = (h ^ uint32(msg[i])) * 16777619
+	}
+	return h % samplerBuckets
+}
@@ -0,0 +1,32 @@
+package pslog
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSamplerCounterNewWindowKeepsConcurrentCounts(t *testing.T) {
+	for range 100 {
+		var c samplerCounter
+		c.inc(0)
+		const workers, perWorker = 8, 100
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Go(func() {
+				for range perWorker {
+					c.inc(1)
+				}
+			})
+		}
+		wg.Wait()
+		if got := c.inc(1); got != workers*perWorker+1 {
+			t.Fatalf("expected %d entries in the new window, got %d", workers*perWorker+1, got)
+		}
+	}
+	var c samplerCounter
+	c.inc(7)
+	c.inc(7)
+	if got := c.inc(8); got != 1 {
+		t.Fatalf("expected a new window to restart the count, got %d", got)
+	}
+}
@@ -0,0 +1,222 @@
+package pslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"pkt.systems/pslog"
+)
+
+func countMessages(lines []string, msg string) int {
+	n := 0
+	for _, line := range lines {
+		if strings.Contains(line, `"msg":"`+msg+`"`) || strings.Contains(line, " "+msg) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSamplingFirstThenEveryMth(t *testing.T) {
+	variants := []struct {
+		name string
+		opts pslog.Options
+	}{
+		{"json_plain", pslog.Options{Mode: pslog.ModeStructured, NoColor: true}},
+		{"json_color", pslog.Options{Mode: pslog.ModeStructured, ForceColor: true}},
+		{"console_plain", pslog.Options{Mode: pslog.ModeConsole, NoColor: true}},
+		{"console_color", pslog.Options{Mode: pslog.ModeConsole, ForceColor: true}},
+	}
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := variant.opts
+			opts.DisableTimestamp = true
+			opts.Sampling = &pslog.Sampling{Interval: time.Hour, First: 3, Thereafter: 5, SummaryInterval: -1}
+			logger := pslog.NewWithOptions(nil, &buf, opts)
+			child := logger.With("svc", "api")
+
+			for range 13 {
+				child.Info("hot")
+			}
+			for range 4 {
+				logger.Debug("hot")
+			}
+			for range 6 {
+				child.Error("hot")
+			}
+
+			lines := strings.Split(stripANSI(strings.TrimSpace(buf.String())), "\n")
+			// 3 first + entries 8 and 13 at info, 3 first at debug, all 6 errors.
+			if len(lines) != 5+3+6 {
+				t.Fatalf("expected 14 lines, got %d:\n%s", len(lines), strings.Join(lines, "\n"))
+			}
+		})
+	}
+}
+
+// suppressedSummaries sums the suppressed counts of the summaries in out.
+func suppressedSummaries(t *testing.T, out string) (total float64, summaries int) {
+	t.Helper()
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var payload map[string]any
+		if err := json.Unmarshal([]byte(line), &payload); err != nil {
+			t.Fatalf("invalid json %q: %v", line, err)
+		}
+		if payload["msg"] != "logger.sampling.suppressed" {
+			continue
+		}
+		if payload["lvl"] != "info" {
+			t.Fatalf("unexpected summary: %v", payload)
+		}
+		n, _ := payload["suppressed"].(float64)
+		total += n
+		summaries++
+	}
+	return total, summaries
+}
+
+func TestSamplingSummaryIsPeriodic(t *testing.T) {
+	var buf syncBuffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		MinLevel:         pslog.WarnLevel,
+		Sampling:         &pslog.Sampling{Interval: time.Hour, First: 1, SummaryInterval: 5 * time.Millisecond},
+	})
+	defer pslog.Close(logger)
+	for range 5 {
+		logger.Warn("hot")
+	}
+	// Nothing is logged after the suppressed entries; the ticker reports them.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if total, _ := suppressedSummaries(t, buf.String()); total == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a summary of 4 suppressed entries, got %q", buf.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); countMessages(lines, "hot") != 1 {
+		t.Fatalf("unexpected sampled output: %v", lines)
+	}
+}
+
+func TestSamplingSummaryFlushedOnClose(t *testing.T) {
+	var buf syncBuffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		Sampling:         &pslog.Sampling{Interval: time.Hour, First: 1, SummaryInterval: time.Hour},
+	})
+	child := logger.With("svc", "api")
+	for range 3 {
+		child.Info("hot")
+	}
+	if err := pslog.Close(child); err != nil {
+		t.Fatalf("close child: %v", err)
+	}
+	if _, summaries := suppressedSummaries(t, buf.String()); summaries != 0 {
+		t.Fatalf("closing a derived logger must not stop the summary, got %q", buf.String())
+	}
+	if err := pslog.Close(logger); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if total, summaries := suppressedSummaries(t, buf.String()); total != 2 || summaries != 1 {
+		t.Fatalf("expected one final summary of 2 entries, got %q", buf.String())
+	}
+	if err := pslog.Close(logger); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+}
+
+func TestSamplingSummaryStopsOnContextCancel(t *testing.T) {
+	var buf syncBuffer
+	ctx, cancel := context.WithCancel(context.Background())
+	logger := pslog.NewWithOptions(ctx, &buf, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		Sampling:         &pslog.Sampling{Interval: time.Hour, First: 1, SummaryInterval: time.Hour},
+	})
+	for range 4 {
+		logger.Info("hot")
+	}
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if total, _ := suppressedSummaries(t, buf.String()); total == 3 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected cancellation to flush the summary, got %q", buf.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSamplingNegativeFirstKeepsOnlyThereafter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		Sampling:         &pslog.Sampling{Interval: time.Hour, First: -1, Thereafter: 4, SummaryInterval: -1},
+	})
+	for i := range 12 {
+		logger.Info("hot", "i", i+1)
+	}
+	lines := collectLines(&buf)
+	want := []string{
+		`{"lvl":"info","msg":"hot","i":4}`,
+		`{"lvl":"info","msg":"hot","i":8}`,
+		`{"lvl":"info","msg":"hot","i":12}`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected output: %v", lines)
+	}
+}
+
+func TestSamplingFromEnv(t *testing.T) {
+	t.Setenv("PSLOG_TEST_SAMPLING", "first=0,thereafter=3,interval=1h,summary=-1s")
+
+	var buf bytes.Buffer
+	logger := pslog.LoggerFromEnv(nil,
+		pslog.WithEnvPrefix("PSLOG_TEST_"),
+		pslog.WithEnvWriter(&buf),
+		pslog.WithEnvOptions(pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true}),
+	)
+	for range 10 {
+		logger.Info("hot")
+	}
+	// first=0 means no leading entries, so only entries 3, 6 and 9 are kept.
+	if lines := collectLines(&buf); len(lines) != 3 {
+		t.Fatalf("expected 2 lines, got %v", lines)
+	}
+}
+
+func TestSamplingAllocatesZero(t *testing.T) {
+	logger := pslog.NewWithOptions(nil, io.Discard, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		Sampling:         &pslog.Sampling{First: 1, Thereafter: 2, SummaryInterval: -1},
+	})
+	keyvals := []any{"key", "value"}
+	logger.Info("msg", keyvals...)
+	allocs := testing.AllocsPerRun(1000, func() {
+		logger.Info("msg", keyvals...)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected 0 allocs with sampling enabled, got %.2f", allocs)
+	}
+}
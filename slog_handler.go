@@ -0,0 +1,186 @@
+package pslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// slogHandler adapts a pslog emitter to the log/slog Handler contract.
+// Attributes and groups map onto With and WithGroup of the underlying logger,
+// so they are pre-encoded into its static payload: structured mode nests
+// groups as objects while console mode flattens them into dotted keys.
+type slogHandler struct {
+	logger  Logger
+	untimed Logger
+	flatten bool
+}
+
+// recordOrigin carries the time and program counter of a log/slog record so
+// the emitters render them instead of resolving their own.
+type recordOrigin struct {
+	time time.Time
+	pc   uintptr
+}
+
+// NewSlogHandler returns a log/slog Handler that renders records through the
+// pslog emitter selected by opts. ctx controls runtime lifecycle;
+// cancellation tears down logger-owned resources.
+//
+// slog levels map onto pslog levels by range: anything below slog.LevelDebug
+// is TraceLevel, and anything at or above slog.LevelError is ErrorLevel.
+// Records are rendered with their own Time, and records with a zero Time
+// without a timestamp. With Options.CallerKeyval, the caller is taken from
+// the record's PC when it is set.
+func NewSlogHandler(ctx context.Context, w io.Writer, opts Options) slog.Handler {
+	logger := buildAdapter(ctx, w, opts)
+	return &slogHandler{
+		logger:  logger,
+		untimed: untimedLogger(logger),
+		flatten: opts.Mode != ModeStructured,
+	}
+}
+
+func untimedLogger(logger Logger) Logger {
+	cl, ok := logger.(coreLogger)
+	if !ok || !cl.core().cfg.includeTimestamp {
+		return logger
+	}
+	return cl.withCoreConfig(func(cfg *coreConfig) {
+		cfg.includeTimestamp = false
+	})
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	cl, ok := h.logger.(coreLogger)
+	if !ok {
+		return true
+	}
+	return cl.core().cfg.shouldLog(levelFromSlog(level))
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	logger := h.logger
+	if r.Time.IsZero() {
+		logger = h.untimed
+	}
+	var keyvals []any
+	if r.NumAttrs() > 0 {
+		keyvals = make([]any, 0, r.NumAttrs()*2)
+		r.Attrs(func(a slog.Attr) bool {
+			keyvals = appendSlogAttr(keyvals, "", a, h.flatten)
+			return true
+		})
+	}
+	cl, ok := logger.(coreLogger)
+	if !ok {
+		logger.Log(levelFromSlog(r.Level), r.Message, keyvals...)
+		return nil
+	}
+	cl.logRecord(&recordOrigin{time: r.Time, pc: r.PC}, levelFromSlog(r.Level), r.Message, keyvals)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	keyvals := make([]any, 0, len(attrs)*2)
+	for _, a := range attrs {
+		keyvals = appendSlogAttr(keyvals, "", a, h.flatten)
+	}
+	if len(keyvals) == 0 {
+		return h
+	}
+	clone := *h
+	clone.logger = h.logger.With(keyvals...)
+	clone.untimed = h.untimed.With(keyvals...)
+	return &clone
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.logger = WithGroup(h.logger, name)
+	clone.untimed = WithGroup(h.untimed, name)
+	return &clone
+}
+
+// appendSlogAttr converts a into key/value pairs. Values are resolved and
+// unwrapped into the native Go types the emitters specialise, so numbers stay
+// numbers and times stay times. Empty attributes and empty groups are dropped
+// and groups with an empty key are inlined.
+func appendSlogAttr(dst []any, prefix string, a slog.Attr, flatten bool) []any {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return dst
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		return append(dst, prefix+a.Key, slogValueAny(a.Value))
+	}
+	attrs := a.Value.Group()
+	if len(attrs) == 0 {
+		return dst
+	}
+	if a.Key == "" {
+		for _, ga := range attrs {
+			dst = appendSlogAttr(dst, prefix, ga, flatten)
+		}
+		return dst
+	}
+	if flatten {
+		nested := prefix + a.Key + "."
+		for _, ga := range attrs {
+			dst = appendSlogAttr(dst, nested, ga, true)
+		}
+		return dst
+	}
+	var group []any
+	for _, ga := range attrs {
+		group = appendSlogAttr(group, "", ga, false)
+	}
+	if len(group) == 0 {
+		return dst
+	}
+	return append(dst, prefix+a.Key, keyvalGroup(group))
+}
+
+func slogValueAny(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return v.Duration()
+	case slog.KindTime:
+		return v.Time()
+	default:
+		return v.Any()
+	}
+}
+
+// levelFromSlog maps a slog.Level onto the closest pslog Level at or below it.
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return TraceLevel
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
@@ -0,0 +1,138 @@
+package pslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"pkt.systems/pslog"
+)
+
+func TestSlogHandlerConformance(t *testing.T) {
+	variants := []struct {
+		name string
+		opts pslog.Options
+	}{
+		{"json", pslog.Options{Mode: pslog.ModeStructured, NoColor: true}},
+		{"json_verbose", pslog.Options{Mode: pslog.ModeStructured, NoColor: true, VerboseFields: true}},
+	}
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			slogtest.Run(t, func(*testing.T) slog.Handler {
+				buf.Reset()
+				return pslog.NewSlogHandler(t.Context(), &buf, variant.opts)
+			}, func(t *testing.T) map[string]any {
+				line := strings.TrimSpace(buf.String())
+				var payload map[string]any
+				if err := json.Unmarshal([]byte(line), &payload); err != nil {
+					t.Fatalf("invalid json %q: %v", line, err)
+				}
+				renameKey(payload, "ts", slog.TimeKey)
+				renameKey(payload, "lvl", slog.LevelKey)
+				renameKey(payload, "message", slog.MessageKey)
+				return payload
+			})
+		})
+	}
+}
+
+func renameKey(payload map[string]any, from, to string) {
+	if value, ok := payload[from]; ok {
+		delete(payload, from)
+		payload[to] = value
+	}
+}
+
+func TestSlogHandlerJSONColorNestsGroups(t *testing.T) {
+	var buf bytes.Buffer
+	handler := pslog.NewSlogHandler(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, ForceColor: true, DisableTimestamp: true})
+	slog.New(handler).WithGroup("http").Info("req", "method", "GET", "status", 200)
+
+	line := stripANSI(strings.TrimSpace(buf.String()))
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(line), &payload); err != nil {
+		t.Fatalf("invalid json %q: %v", line, err)
+	}
+	group, ok := payload["http"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected nested http object, got %v", payload)
+	}
+	if group["method"] != "GET" || group["status"] != float64(200) {
+		t.Fatalf("unexpected group payload: %v", group)
+	}
+}
+
+func TestSlogHandlerConsoleFlattensGroups(t *testing.T) {
+	var buf bytes.Buffer
+	handler := pslog.NewSlogHandler(nil, &buf, pslog.Options{Mode: pslog.ModeConsole, NoColor: true, DisableTimestamp: true})
+	logger := slog.New(handler).With("svc", "api").WithGroup("http").With("method", "GET")
+	logger.Info("req", slog.Group("client", "ip", "10.0.0.1"), "status", 200)
+
+	got := strings.TrimSpace(buf.String())
+	want := "INF req svc=api http.method=GET http.client.ip=10.0.0.1 http.status=200"
+	if got != want {
+		t.Fatalf("unexpected console output:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestSlogHandlerLevelMapping(t *testing.T) {
+	cases := []struct {
+		level slog.Level
+		want  string
+	}{
+		{slog.LevelDebug - 4, "trace"},
+		{slog.LevelDebug, "debug"},
+		{slog.LevelInfo, "info"},
+		{slog.LevelWarn, "warn"},
+		{slog.LevelError, "error"},
+		{slog.LevelError + 4, "error"},
+	}
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		handler := pslog.NewSlogHandler(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, NoColor: true, DisableTimestamp: true, MinLevel: pslog.TraceLevel})
+		slog.New(handler).Log(t.Context(), tc.level, "msg")
+		var payload map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+			t.Fatalf("invalid json %q: %v", buf.String(), err)
+		}
+		if payload["lvl"] != tc.want {
+			t.Fatalf("level %v: expected %q, got %v", tc.level, tc.want, payload["lvl"])
+		}
+	}
+}
+
+func TestSlogHandlerEnabledFollowsMinLevel(t *testing.T) {
+	handler := pslog.NewSlogHandler(nil, &bytes.Buffer{}, pslog.Options{Mode: pslog.ModeStructured, MinLevel: pslog.WarnLevel})
+	if handler.Enabled(t.Context(), slog.LevelInfo) {
+		t.Fatalf("expected info to be disabled")
+	}
+	if !handler.Enabled(t.Context(), slog.LevelError) {
+		t.Fatalf("expected error to be enabled")
+	}
+}
+
+func TestSlogHandlerUsesRecordTimeAndPC(t *testing.T) {
+	var buf bytes.Buffer
+	handler := pslog.NewSlogHandler(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, NoColor: true, UTC: true, CallerKeyval: true})
+	when := time.Date(2024, time.March, 4, 5, 6, 7, 0, time.UTC)
+	if err := handler.Handle(t.Context(), slog.NewRecord(when, slog.LevelInfo, "bridged", slogRecordSite())); err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+	want := `{"ts":"2024-03-04T05:06:07Z","lvl":"info","msg":"bridged","fn":"slogRecordSite"}`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Fatalf("unexpected output:\n got %s\nwant %s", got, want)
+	}
+}
+
+//go:noinline
+func slogRecordSite() uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	return pcs[0]
+}
@@ -0,0 +1,246 @@
+package pslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// slogLogger implements Logger on top of an arbitrary slog.Handler. Its
+// loggerBase only carries level state: cfg.writer is io.Discard and is never
+// written to, every entry is handed to the handler as a slog.Record.
+//
+// The logger name and the loglevel field stay at the top level like in the
+// native emitters, so they are attached to root, the handler as it was before
+// the first WithGroup, and scopes replays everything applied since.
+type slogLogger struct {
+	handler slog.Handler
+	root    slog.Handler
+	scopes  []slogScope
+	base    loggerBase
+}
+
+// slogScope is one WithGroup (group set) or grouped WithAttrs call.
+type slogScope struct {
+	group string
+	attrs []slog.Attr
+}
+
+// LoggerFromSlogHandler returns a Logger that converts every entry into a
+// slog.Record and passes it to handler. With follows the same key rules as
+// the native emitters (a single error becomes an "error" field, a trailing
+// value without a key becomes argN) and is forwarded to handler.WithAttrs.
+// The logger name and the loglevel field stay at the top level, outside any
+// group opened with WithGroup.
+//
+// The returned logger starts at TraceLevel so filtering is left to the
+// handler's Enabled method; LogLevel and LogLevelFromEnv add a pslog-side
+// threshold on top. Fatal exits the process and Panic panics after the record
+// has been handled.
+func LoggerFromSlogHandler(handler slog.Handler) Logger {
+	if handler == nil {
+		return noopLogger{}
+	}
+	cfg := coreConfig{
+		writer:        io.Discard,
+		minLevel:      TraceLevel,
+		logLevelValue: LevelString(TraceLevel),
+	}
+	return &slogLogger{handler: handler, root: handler, base: newLoggerBase(cfg, nil)}
+}
+
+func (l *slogLogger) Trace(msg string, keyvals ...any) { l.log(TraceLevel, msg, keyvals) }
+func (l *slogLogger) Debug(msg string, keyvals ...any) { l.log(DebugLevel, msg, keyvals) }
+func (l *slogLogger) Info(msg string, keyvals ...any)  { l.log(InfoLevel, msg, keyvals) }
+func (l *slogLogger) Warn(msg string, keyvals ...any)  { l.log(WarnLevel, msg, keyvals) }
+func (l *slogLogger) Error(msg string, keyvals ...any) { l.log(ErrorLevel, msg, keyvals) }
+
+func (l *slogLogger) Fatal(msg string, keyvals ...any) {
+	l.log(FatalLevel, msg, keyvals)
+	l.base.cfg.fatalExit(ownerToken(l))
+}
+
+func (l *slogLogger) Panic(msg string, keyvals ...any) {
+	l.log(PanicLevel, msg, keyvals)
+	panic(msg)
+}
+
+func (l *slogLogger) Log(level Level, msg string, keyvals ...any) {
+	l.log(level, msg, keyvals)
+}
+
+func (l *slogLogger) log(level Level, msg string, keyvals []any) {
+	if !l.base.cfg.shouldLog(level) {
+		return
+	}
+	ctx := context.Background()
+	slogLevel := slogLevelFromLevel(level)
+	if !l.handler.Enabled(ctx, slogLevel) {
+		return
+	}
+	var pcs [1]uintptr
+	// Skip runtime.Callers, log and the public Logger method.
+	runtime.Callers(3, pcs[:])
+	record := slog.NewRecord(time.Now(), slogLevel, msg, pcs[0])
+	if len(keyvals) == 1 {
+		// Per-call keyvals follow the emitter rules, where a lone value is
+		// argN even when it is an error.
+		record.AddAttrs(slogAttrFromField(field{key: argKeyName(0), value: keyvals[0]}))
+	} else {
+		for _, f := range collectFields(keyvals, false) {
+			if f.key == "" {
+				continue
+			}
+			record.AddAttrs(slogAttrFromField(f))
+		}
+	}
+	_ = l.handler.Handle(ctx, record)
+}
+
+// rebuildHandler attaches the logger name and the loglevel field to root and
+// replays the grouped scopes on top, so both stay outside every group.
+func (l *slogLogger) rebuildHandler() {
+	handler := l.root
+	var attrs []slog.Attr
+	if l.base.cfg.name != "" {
+		attrs = append(attrs, slog.String(loggerNameKey, l.base.cfg.name))
+	}
+	if l.base.cfg.includeLogLevel {
+		attrs = append(attrs, slog.String("loglevel", l.base.cfg.logLevelLabel()))
+	}
+	if len(attrs) > 0 {
+		handler = handler.WithAttrs(attrs)
+	}
+	for _, scope := range l.scopes {
+		if scope.group != "" {
+			handler = handler.WithGroup(scope.group)
+		} else {
+			handler = handler.WithAttrs(scope.attrs)
+		}
+	}
+	l.handler = handler
+}
+
+func (l *slogLogger) With(keyvals ...any) Logger {
+	fields := collectFields(keyvals, l.base.cfg.errors != nil)
+	if len(fields) == 0 {
+		return l
+	}
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		if f.key == "" {
+			continue
+		}
+		attrs = append(attrs, slogAttrFromField(f))
+	}
+	if len(attrs) == 0 {
+		return l
+	}
+	clone := *l
+	clone.handler = l.handler.WithAttrs(attrs)
+	if len(l.scopes) == 0 {
+		clone.root = l.root.WithAttrs(attrs)
+	} else {
+		clone.scopes = append(slices.Clip(l.scopes), slogScope{attrs: attrs})
+	}
+	clone.base = l.base.clone()
+	clone.base.withFields(fields)
+	return &clone
+}
+
+func (l *slogLogger) WithLogLevel() Logger {
+	if l.base.cfg.includeLogLevel {
+		return l
+	}
+	clone := *l
+	clone.base = l.base.clone()
+	clone.base.withLogLevelField()
+	clone.rebuildHandler()
+	return &clone
+}
+
+func (l *slogLogger) LogLevel(level Level) Logger {
+	clone := *l
+	clone.base = l.base.clone()
+	if level == NoLevel {
+		clone.base.withForcedLevel(level)
+	} else {
+		clone.base.withMinLevel(level)
+	}
+	if clone.base.cfg.includeLogLevel {
+		clone.rebuildHandler()
+	}
+	return &clone
+}
+
+func (l *slogLogger) LogLevelFromEnv(key string) Logger {
+	if level, ok := LevelFromEnv(key); ok {
+		return l.LogLevel(level)
+	}
+	return l
+}
+
+func (l *slogLogger) Named(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	clone.base = l.base.clone()
+	clone.base.withName(name)
+	clone.rebuildHandler()
+	return &clone
+}
+
+func (l *slogLogger) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	clone.handler = l.handler.WithGroup(name)
+	clone.scopes = append(slices.Clip(l.scopes), slogScope{group: name})
+	clone.base = l.base.clone()
+	clone.base.withGroup(name)
+	return &clone
+}
+
+func slogAttrFromField(f field) slog.Attr {
+	switch v := f.value.(type) {
+	case TrustedString:
+		return slog.String(f.key, string(v))
+	case keyvalGroup:
+		var attrs []any
+		for _, gf := range collectFields(v, false) {
+			attrs = append(attrs, slogAttrFromField(gf))
+		}
+		return slog.Group(f.key, attrs...)
+	default:
+		return slog.Any(f.key, v)
+	}
+}
+
+// slogLevelFromLevel maps a pslog Level onto slog's numeric scale. Trace sits
+// one step below Debug and Fatal/Panic continue above Error in steps of four.
+// NoLevel entries are reported at Info.
+func slogLevelFromLevel(level Level) slog.Level {
+	switch level {
+	case TraceLevel:
+		return slog.LevelDebug - 4
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel, NoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	case FatalLevel:
+		return slog.LevelError + 4
+	case PanicLevel:
+		return slog.LevelError + 8
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,137 @@
+package pslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func decodeSlogJSON(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	line := strings.TrimSpace(buf.String())
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(line), &payload); err != nil {
+		t.Fatalf("invalid json %q: %v", line, err)
+	}
+	return payload
+}
+
+func TestLoggerFromSlogHandlerFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := LoggerFromSlogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug - 4}))
+
+	logger.With(errors.New("boom")).Info("with error", "user", "alice", 42)
+	payload := decodeSlogJSON(t, &buf)
+	if payload["msg"] != "with error" || payload["level"] != "INFO" {
+		t.Fatalf("unexpected record: %v", payload)
+	}
+	if payload["error"] != "boom" {
+		t.Fatalf("expected With(err) to become error field, got %v", payload)
+	}
+	if payload["user"] != "alice" || payload["arg1"] != float64(42) {
+		t.Fatalf("unexpected runtime fields: %v", payload)
+	}
+
+	buf.Reset()
+	logger.Debug("lone", errors.New("oops"))
+	payload = decodeSlogJSON(t, &buf)
+	if payload["arg0"] != "oops" {
+		t.Fatalf("expected lone runtime value under arg0, got %v", payload)
+	}
+}
+
+func TestLoggerFromSlogHandlerLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := LoggerFromSlogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug - 4}))
+
+	logger.Trace("trace")
+	if payload := decodeSlogJSON(t, &buf); payload["level"] != "DEBUG-4" {
+		t.Fatalf("expected trace to map below debug, got %v", payload["level"])
+	}
+
+	buf.Reset()
+	warnOnly := logger.LogLevel(WarnLevel).WithLogLevel()
+	warnOnly.Info("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("expected info to be filtered, got %q", buf.String())
+	}
+	warnOnly.Warn("shown")
+	payload := decodeSlogJSON(t, &buf)
+	if payload["level"] != "WARN" || payload["loglevel"] != "warn" {
+		t.Fatalf("unexpected warn record: %v", payload)
+	}
+
+	buf.Reset()
+	t.Setenv("PSLOG_TEST_SLOG_LEVEL", "error")
+	logger.LogLevelFromEnv("PSLOG_TEST_SLOG_LEVEL").Warn("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("expected env level to filter warn, got %q", buf.String())
+	}
+}
+
+func TestLoggerFromSlogHandlerRespectsHandlerEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := LoggerFromSlogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	logger.Info("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("expected handler level to filter info, got %q", buf.String())
+	}
+}
+
+func TestLoggerFromSlogHandlerFatalAndPanic(t *testing.T) {
+	var buf bytes.Buffer
+	logger := LoggerFromSlogHandler(slog.NewJSONHandler(&buf, nil))
+
+	called := false
+	origExit := exitProcess
+	exitProcess = func(int) { called = true }
+	t.Cleanup(func() { exitProcess = origExit })
+
+	logger.Fatal("fatal")
+	if !called {
+		t.Fatalf("exitProcess not called")
+	}
+	if payload := decodeSlogJSON(t, &buf); payload["level"] != "ERROR+4" {
+		t.Fatalf("unexpected fatal level: %v", payload["level"])
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	logger.Panic("panic")
+}
+
+func TestLoggerFromSlogHandlerSource(t *testing.T) {
+	var buf bytes.Buffer
+	logger := LoggerFromSlogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true}))
+	logger.Info("src")
+	payload := decodeSlogJSON(t, &buf)
+	source, ok := payload["source"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected source object, got %v", payload)
+	}
+	if fn, _ := source["function"].(string); !strings.HasSuffix(fn, "TestLoggerFromSlogHandlerSource") {
+		t.Fatalf("expected caller to be the test function, got %v", source["function"])
+	}
+}
+
+func TestLoggerFromSlogHandlerKeepsNameAndLogLevelOutsideGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := WithGroup(LoggerFromSlogHandler(slog.NewJSONHandler(&buf, nil)).With("svc", "api"), "req")
+	logger = Named(logger.With("id", 7), "http").WithLogLevel().LogLevel(InfoLevel)
+	Named(logger, "router").Info("served", "status", 200)
+
+	payload := decodeSlogJSON(t, &buf)
+	if payload["logger"] != "http.router" || payload["loglevel"] != "info" || payload["svc"] != "api" {
+		t.Fatalf("expected name, loglevel and ungrouped fields at the top level, got %v", payload)
+	}
+	req, ok := payload["req"].(map[string]any)
+	if !ok || len(req) != 2 || req["id"] != float64(7) || req["status"] != float64(200) {
+		t.Fatalf("expected only the grouped fields inside req, got %v", payload)
+	}
+}
@@ -0,0 +1,98 @@
+package pslog
+
+import (
+	"runtime"
+	"strconv"
+)
+
+// maxStackDepth bounds the number of frames captured for Options.StackTraceLevel.
+const maxStackDepth = 64
+
+// stackKey is the field that carries the captured stack in structured output.
+const stackKey = "stack"
+
+// stackFrames renders as a JSON array of "function file:line" strings.
+type stackFrames []string
+
+// MarshalLogArray implements ArrayMarshaler.
+func (s stackFrames) MarshalLogArray(enc *ArrayEncoder) {
+	for _, frame := range s {
+		enc.AppendString(frame)
+	}
+}
+
+// stackCapture attaches the goroutine's stack to entries at or above level.
+// It runs as a hook so loggers without it keep the regular hot path.
+type stackCapture struct {
+	level   Level
+	console bool
+}
+
+// newStackCapture returns nil, leaving capture disabled, when level is the
+// zero Level and enabled is false, or when level is NoLevel or above.
+func newStackCapture(level Level, enabled, console bool) *stackCapture {
+	if (level == DebugLevel && !enabled) || level >= NoLevel {
+		return nil
+	}
+	return &stackCapture{level: level, console: console}
+}
+
+// hook records the stack. Structured loggers get a `stack` field; console
+// loggers keep the frames on the entry and print them as an indented block
+// below the line.
+func (s *stackCapture) hook(e *Entry) bool {
+	if e.Level < s.level || e.Level >= NoLevel {
+		return true
+	}
+	attachStack(e, captureStack(), s.console)
+	return true
+}
+
+// attachStack stores frames on the entry: as a `stack` field for structured
+// loggers, or for printing below the line for console loggers.
+func attachStack(e *Entry, frames []string, console bool) {
+	if len(frames) == 0 {
+		return
+	}
+	if console {
+		e.stack = frames
+		return
+	}
+	e.Set(stackKey, stackFrames(frames))
+}
+
+// captureStack returns the calling goroutine's frames, innermost first, with
+// pslog and log/slog frames skipped the same way callerValue does.
+func captureStack() []string {
+	var pcs [maxStackDepth]uintptr
+	// Skip runtime.Callers and captureStack.
+	n := runtime.Callers(2, pcs[:])
+	if n == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames(pcs[:n])
+	var out []string
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isInternalFunction(frame.Function) {
+			out = append(out, formatFrame(frame))
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+// formatFrame renders frame as "function file:line".
+func formatFrame(frame runtime.Frame) string {
+	return frame.Function + " " + frame.File + ":" + strconv.Itoa(frame.Line)
+}
+
+// writeConsoleStack writes frames as an indented block following the line.
+func writeConsoleStack(lw *lineWriter, frames []string) {
+	for _, frame := range frames {
+		lw.writeString("\n    ")
+		lw.writeString(frame)
+	}
+}
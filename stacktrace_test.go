@@ -0,0 +1,98 @@
+package pslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"pkt.systems/pslog"
+)
+
+func TestStackTraceLevelJSON(t *testing.T) {
+	for _, noColor := range []bool{true, false} {
+		var buf bytes.Buffer
+		logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+			Mode:             pslog.ModeStructured,
+			DisableTimestamp: true,
+			NoColor:          noColor,
+			ForceColor:       !noColor,
+			StackTraceLevel:  pslog.ErrorLevel,
+		}).With("svc", "api")
+		logger.Warn("below threshold")
+		logger.Error("failed", "attempt", 2)
+
+		lines := strings.Split(strings.TrimSpace(stripANSI(buf.String())), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 lines, got %q", buf.String())
+		}
+		if strings.Contains(lines[0], `"stack"`) {
+			t.Fatalf("unexpected stack below threshold: %s", lines[0])
+		}
+		var entry struct {
+			Attempt int      `json:"attempt"`
+			Stack   []string `json:"stack"`
+		}
+		if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+			t.Fatalf("invalid JSON %q: %v", lines[1], err)
+		}
+		if entry.Attempt != 2 || len(entry.Stack) == 0 {
+			t.Fatalf("missing fields: %s", lines[1])
+		}
+		if !strings.HasPrefix(entry.Stack[0], "pkt.systems/pslog_test.TestStackTraceLevelJSON ") || !strings.Contains(entry.Stack[0], "stacktrace_test.go:") {
+			t.Fatalf("expected the test function as innermost frame, got %q", entry.Stack[0])
+		}
+		for _, frame := range entry.Stack {
+			if strings.HasPrefix(frame, "pkt.systems/pslog.") {
+				t.Fatalf("pslog frame not skipped: %q", frame)
+			}
+		}
+	}
+}
+
+func TestStackTraceLevelConsoleBlock(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeConsole,
+		DisableTimestamp: true,
+		NoColor:          true,
+		StackTraceLevel:  pslog.ErrorLevel,
+	})
+	logger.Error("failed", "attempt", 2)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < 2 || lines[0] != "ERR failed attempt=2" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "    pkt.systems/pslog_test.TestStackTraceLevelConsoleBlock ") {
+		t.Fatalf("expected indented frame, got %q", lines[1])
+	}
+}
+
+func TestStackTraceLevelZeroDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{Mode: pslog.ModeStructured, DisableTimestamp: true, NoColor: true})
+	logger.Error("failed")
+	if got, want := strings.TrimSpace(buf.String()), `{"lvl":"error","msg":"failed"}`; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
+
+func TestStackTraceEnabledAtDebugLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := pslog.NewWithOptions(nil, &buf, pslog.Options{
+		Mode:             pslog.ModeStructured,
+		DisableTimestamp: true,
+		NoColor:          true,
+		MinLevel:         pslog.TraceLevel,
+		StackTraceLevel:  pslog.DebugLevel,
+		EnableStackTrace: true,
+	})
+	logger.Trace("skipped")
+	logger.Debug("captured")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || strings.Contains(lines[0], `"stack"`) || !strings.Contains(lines[1], `"stack":["pkt.systems/pslog_test.TestStackTraceEnabledAtDebugLevel `) {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,584 @@
+package pslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFormat selects how a SyslogWriter fills the RFC 5424 message.
+type SyslogFormat uint8
+
+const (
+	// SyslogStructuredData moves the entry's fields into a STRUCTURED-DATA
+	// element and sends the message text as MSG. This is the default.
+	SyslogStructuredData SyslogFormat = iota
+	// SyslogJSON sends the JSON line unchanged as MSG, with no
+	// STRUCTURED-DATA.
+	SyslogJSON
+)
+
+// Syslog facilities, as defined by RFC 5424.
+const (
+	SyslogKern     = 0
+	SyslogUser     = 1
+	SyslogMail     = 2
+	SyslogDaemon   = 3
+	SyslogAuth     = 4
+	SyslogSyslog   = 5
+	SyslogLPR      = 6
+	SyslogNews     = 7
+	SyslogUUCP     = 8
+	SyslogCron     = 9
+	SyslogAuthPriv = 10
+	SyslogFTP      = 11
+	SyslogLocal0   = 16
+	SyslogLocal1   = 17
+	SyslogLocal2   = 18
+	SyslogLocal3   = 19
+	SyslogLocal4   = 20
+	SyslogLocal5   = 21
+	SyslogLocal6   = 22
+	SyslogLocal7   = 23
+)
+
+const (
+	defaultSyslogSDID              = "pslog@32473"
+	defaultSyslogDialTimeout       = 5 * time.Second
+	defaultSyslogWriteTimeout      = 5 * time.Second
+	defaultSyslogReconnectInterval = time.Second
+	syslogTimestampLayout          = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// SyslogOptions configures a SyslogWriter.
+type SyslogOptions struct {
+	// Facility is the RFC 5424 facility code. Zero selects SyslogUser, as
+	// the kernel facility is reserved.
+	Facility int
+
+	// AppName, Hostname and MsgID fill the header fields. AppName defaults
+	// to the executable name and Hostname to os.Hostname; MsgID defaults to
+	// "-".
+	AppName  string
+	Hostname string
+	MsgID    string
+
+	// Format selects STRUCTURED-DATA (default) or JSON messages.
+	Format SyslogFormat
+
+	// SDID is the SD-ID of the element carrying the fields. Defaults to
+	// "pslog@32473". 32473 is the private enterprise number RFC 5612
+	// reserves for documentation, so the default is only a placeholder:
+	// deployments that forward structured data should set an SD-ID under
+	// their own IANA enterprise number.
+	SDID string
+
+	// DialTimeout and WriteTimeout bound connection attempts and writes.
+	// Both default to five seconds.
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// ReconnectInterval is the minimum time between connection attempts
+	// after a failure. Reconnects are dialled in the background; entries
+	// written while disconnected or while a dial is in progress are dropped
+	// and reported through Write's error. Defaults to one second.
+	ReconnectInterval time.Duration
+}
+
+// SyslogWriter sends pslog's structured JSON lines to a syslog server as RFC
+// 5424 messages. The entry level selects the severity: trace and debug map to
+// debug, info and lines without a level to info, warn to warning, error to
+// err, fatal to crit and panic to alert. Lines that are not JSON objects are
+// sent as the message at info severity.
+//
+// UDP and unix datagram sockets carry one message per datagram; TCP and unix
+// stream sockets use RFC 6587 octet-counting framing. A failed write closes
+// the connection and starts a reconnect on a background goroutine, so Write
+// never waits for a dial. It is safe for concurrent use.
+type SyslogWriter struct {
+	network string
+	address string
+	opts    SyslogOptions
+	header  string // " HOSTNAME APP-NAME PROCID MSGID "
+
+	mu          sync.Mutex
+	conn        net.Conn
+	stream      bool
+	lastAttempt time.Time
+	dialing     bool
+	dialErr     error
+	closed      bool
+	buf         []byte
+	frame       []byte
+}
+
+// NewSyslogWriter connects to a syslog server. network is "udp", "tcp" or
+// "unix"; for "unix" a datagram socket is tried before a stream socket.
+func NewSyslogWriter(network, address string, opts SyslogOptions) (*SyslogWriter, error) {
+	switch network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("syslog: unsupported network %q", network)
+	}
+	if opts.Facility <= 0 || opts.Facility > SyslogLocal7 {
+		opts.Facility = SyslogUser
+	}
+	if opts.AppName == "" && len(os.Args) > 0 {
+		opts.AppName = filepath.Base(os.Args[0])
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	if opts.SDID == "" {
+		opts.SDID = defaultSyslogSDID
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = defaultSyslogDialTimeout
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = defaultSyslogWriteTimeout
+	}
+	if opts.ReconnectInterval <= 0 {
+		opts.ReconnectInterval = defaultSyslogReconnectInterval
+	}
+	w := &SyslogWriter{
+		network: network,
+		address: address,
+		opts:    opts,
+		header: " " + syslogHeaderField(opts.Hostname, 255) +
+			" " + syslogHeaderField(opts.AppName, 48) +
+			" " + strconv.Itoa(os.Getpid()) +
+			" " + syslogHeaderField(opts.MsgID, 32) + " ",
+	}
+	conn, stream, err := w.dial()
+	if err != nil {
+		return nil, err
+	}
+	w.conn, w.stream, w.lastAttempt = conn, stream, time.Now()
+	return w, nil
+}
+
+// Write sends each line in p as one syslog message.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	var errs []error
+	for line := range bytes.SplitSeq(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		w.buf = w.appendMessage(w.buf[:0], line, time.Now())
+		if err := w.send(w.buf); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection. It is safe to call more than once.
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// dial connects to the server. It only reads fields that are fixed at
+// construction, so it runs without w.mu.
+func (w *SyslogWriter) dial() (net.Conn, bool, error) {
+	network := w.network
+	if network == "unix" {
+		if conn, err := net.DialTimeout("unixgram", w.address, w.opts.DialTimeout); err == nil {
+			return conn, false, nil
+		}
+	}
+	conn, err := net.DialTimeout(network, w.address, w.opts.DialTimeout)
+	if err != nil {
+		return nil, false, fmt.Errorf("syslog: dial %s %s: %w", network, w.address, err)
+	}
+	return conn, strings.HasPrefix(network, "tcp") || network == "unix", nil
+}
+
+// reconnect dials on a background goroutine unless a dial is already running
+// or the last attempt started less than ReconnectInterval ago. w.mu must be
+// held.
+func (w *SyslogWriter) reconnect() {
+	if w.dialing || w.closed || time.Since(w.lastAttempt) < w.opts.ReconnectInterval {
+		return
+	}
+	w.dialing = true
+	w.lastAttempt = time.Now()
+	go func() {
+		conn, stream, err := w.dial()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.dialing = false
+		w.dialErr = err
+		if err != nil {
+			return
+		}
+		if w.closed {
+			_ = conn.Close()
+			return
+		}
+		w.conn, w.stream = conn, stream
+	}()
+}
+
+// send writes msg. While disconnected it drops msg and makes sure a reconnect
+// is under way.
+func (w *SyslogWriter) send(msg []byte) error {
+	if w.conn == nil {
+		w.reconnect()
+		if w.dialErr != nil {
+			return fmt.Errorf("syslog: not connected: %w", w.dialErr)
+		}
+		return errors.New("syslog: not connected")
+	}
+	if err := w.writeConn(msg); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		w.reconnect()
+		return fmt.Errorf("syslog: write: %w", err)
+	}
+	return nil
+}
+
+func (w *SyslogWriter) writeConn(msg []byte) error {
+	payload := msg
+	if w.stream {
+		w.frame = strconv.AppendInt(w.frame[:0], int64(len(msg)), 10)
+		w.frame = append(w.frame, ' ')
+		w.frame = append(w.frame, msg...)
+		payload = w.frame
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(w.opts.WriteTimeout))
+	_, err := w.conn.Write(payload)
+	return err
+}
+
+// appendMessage renders line as an RFC 5424 message.
+func (w *SyslogWriter) appendMessage(dst, line []byte, now time.Time) []byte {
+	entry, ok := parseStructuredLine(line, w.opts.Format == SyslogStructuredData)
+	severity := 6
+	if ok {
+		severity = syslogSeverity(entry.level)
+	}
+	dst = append(dst, '<')
+	dst = strconv.AppendInt(dst, int64(w.opts.Facility*8+severity), 10)
+	dst = append(dst, ">1 "...)
+	dst = now.AppendFormat(dst, syslogTimestampLayout)
+	dst = append(dst, w.header...)
+	switch {
+	case !ok || w.opts.Format == SyslogJSON:
+		dst = append(dst, '-', ' ')
+		return append(dst, line...)
+	case len(entry.fields) == 0:
+		dst = append(dst, '-')
+	default:
+		dst = append(dst, '[')
+		dst = append(dst, w.opts.SDID...)
+		for _, field := range entry.fields {
+			dst = append(dst, ' ')
+			dst = append(dst, sdParamName(field.name)...)
+			dst = append(dst, '=', '"')
+			dst = appendSDParamValue(dst, field.value)
+			dst = append(dst, '"')
+		}
+		dst = append(dst, ']')
+	}
+	if entry.msg != "" {
+		dst = append(dst, ' ')
+		dst = append(dst, entry.msg...)
+	}
+	return dst
+}
+
+func syslogSeverity(level Level) int {
+	switch level {
+	case TraceLevel, DebugLevel:
+		return 7
+	case WarnLevel:
+		return 4
+	case ErrorLevel:
+		return 3
+	case FatalLevel:
+		return 2
+	case PanicLevel:
+		return 1
+	default:
+		return 6
+	}
+}
+
+// lineField is one flattened field of a structured line.
+type lineField struct {
+	name  string
+	value string
+}
+
+// structuredLine is a JSON line split into level, message and fields.
+type structuredLine struct {
+	level  Level
+	msg    string
+	fields []lineField
+}
+
+// lineKeys are the keys an emitter writes the timestamp, level and message
+// under: ts/lvl/msg by default, time/level/message with VerboseFields.
+type lineKeys struct {
+	ts, level, msg string
+}
+
+var (
+	compactLineKeys = lineKeys{ts: "ts", level: "lvl", msg: "msg"}
+	verboseLineKeys = lineKeys{ts: "time", level: "level", msg: "message"}
+)
+
+// lineKeysFor returns the key set key belongs to, if any.
+func lineKeysFor(key string) (lineKeys, bool) {
+	switch key {
+	case "ts", "lvl", "msg":
+		return compactLineKeys, true
+	case "time", "level", "message":
+		return verboseLineKeys, true
+	}
+	return lineKeys{}, false
+}
+
+// parseStructuredLine reads the level, the message and, when withFields is
+// set, the remaining fields of a JSON line. The timestamp is dropped; syslog
+// and the journal stamp entries themselves.
+//
+// The emitter writes its own keys before any field, so the first
+// timestamp, level or message key picks the key set, and only the first
+// occurrence of each of its keys is taken. Every other member, including a
+// user field named time, level or message, is kept as a field.
+func parseStructuredLine(line []byte, withFields bool) (structuredLine, bool) {
+	entry := structuredLine{level: NoLevel}
+	if len(line) == 0 || line[0] != '{' {
+		return entry, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if _, err := dec.Token(); err != nil {
+		return entry, false
+	}
+	var (
+		keys                     lineKeys
+		haveKeys                 bool
+		seenTS, seenLvl, seenMsg bool
+	)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return entry, false
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return entry, false
+		}
+		if !haveKeys {
+			keys, haveKeys = lineKeysFor(key)
+		}
+		switch {
+		case haveKeys && key == keys.ts && !seenTS:
+			seenTS = true
+		case haveKeys && key == keys.level && !seenLvl:
+			seenLvl = true
+			if level, ok := ParseLevel(jsonString(raw)); ok {
+				entry.level = level
+			}
+		case haveKeys && key == keys.msg && !seenMsg:
+			seenMsg = true
+			entry.msg = jsonString(raw)
+		default:
+			if withFields {
+				entry.fields = appendLineFields(entry.fields, key, raw)
+			}
+		}
+	}
+	return entry, true
+}
+
+// appendLineFields flattens raw into fields. Nested objects become dotted
+// names; arrays keep their JSON encoding and strings are unquoted.
+func appendLineFields(fields []lineField, name string, raw json.RawMessage) []lineField {
+	switch {
+	case len(raw) > 0 && raw[0] == '{':
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		if _, err := dec.Token(); err != nil {
+			break
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return fields
+			}
+			key, _ := tok.(string)
+			var nested json.RawMessage
+			if err := dec.Decode(&nested); err != nil {
+				return fields
+			}
+			fields = appendLineFields(fields, name+"."+key, nested)
+		}
+		return fields
+	case len(raw) > 0 && raw[0] == '"':
+		return append(fields, lineField{name: name, value: jsonString(raw)})
+	}
+	return append(fields, lineField{name: name, value: string(raw)})
+}
+
+func jsonString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return string(raw)
+	}
+	return s
+}
+
+// sdParamName maps a field name onto the PARAM-NAME grammar: at most 32
+// printable ASCII characters other than '=', ' ', ']' and '"'.
+func sdParamName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name) && b.Len() < 32; i++ {
+		c := name[i]
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		b.WriteByte(c)
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// appendSDParamValue escapes '"', '\' and ']' as required by RFC 5424.
+func appendSDParamValue(dst []byte, value string) []byte {
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"', '\\', ']':
+			dst = append(dst, '\\', c)
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+// syslogHeaderField returns value as PRINTUSASCII of at most limit bytes, or
+// the NILVALUE.
+func syslogHeaderField(value string, limit int) string {
+	var b strings.Builder
+	for i := 0; i < len(value) && b.Len() < limit; i++ {
+		c := value[i]
+		if c < '!' || c > '~' {
+			c = '_'
+		}
+		b.WriteByte(c)
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
+
+var syslogFacilities = map[string]int{
+	"kern": SyslogKern, "user": SyslogUser, "mail": SyslogMail, "daemon": SyslogDaemon,
+	"auth": SyslogAuth, "syslog": SyslogSyslog, "lpr": SyslogLPR, "news": SyslogNews,
+	"uucp": SyslogUUCP, "cron": SyslogCron, "authpriv": SyslogAuthPriv, "ftp": SyslogFTP,
+	"local0": SyslogLocal0, "local1": SyslogLocal1, "local2": SyslogLocal2, "local3": SyslogLocal3,
+	"local4": SyslogLocal4, "local5": SyslogLocal5, "local6": SyslogLocal6, "local7": SyslogLocal7,
+}
+
+// openSyslogOutput parses the LOG_OUTPUT forms syslog://host[:port],
+// syslog+udp://host:port, syslog+tcp://host:port and syslog+unix:///dev/log,
+// with optional facility, app, hostname, msgid, format (sd|json) and sdid
+// query parameters.
+func openSyslogOutput(spec string) (*SyslogWriter, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	network := "udp"
+	if _, transport, ok := strings.Cut(strings.ToLower(u.Scheme), "+"); ok {
+		network = transport
+	}
+	address := u.Host
+	switch network {
+	case "udp", "tcp":
+		if address == "" {
+			return nil, errors.New("missing host")
+		}
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, "514")
+		}
+	case "unix", "unixgram":
+		address = u.Path
+		if address == "" {
+			return nil, errors.New("missing socket path")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported transport %q", network)
+	}
+	var opts SyslogOptions
+	for key, list := range u.Query() {
+		value := strings.TrimSpace(list[len(list)-1])
+		switch strings.ToLower(key) {
+		case "facility":
+			facility, ok := syslogFacilities[strings.ToLower(value)]
+			if !ok {
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 0 || n > SyslogLocal7 {
+					return nil, fmt.Errorf("facility: unknown facility %q", value)
+				}
+				facility = n
+			}
+			opts.Facility = facility
+		case "app", "appname":
+			opts.AppName = value
+		case "hostname", "host":
+			opts.Hostname = value
+		case "msgid":
+			opts.MsgID = value
+		case "sdid":
+			opts.SDID = value
+		case "format":
+			switch strings.ToLower(value) {
+			case "sd", "structured":
+				opts.Format = SyslogStructuredData
+			case "json":
+				opts.Format = SyslogJSON
+			default:
+				return nil, fmt.Errorf("format: unknown format %q", value)
+			}
+		default:
+			return nil, fmt.Errorf("unknown parameter %q", key)
+		}
+	}
+	return NewSyslogWriter(network, address, opts)
+}
@@ -0,0 +1,303 @@
+package pslog
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readOctetCounted reads one RFC 6587 octet-counted frame.
+func readOctetCounted(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	length, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatalf("read frame length: %v", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	if err != nil {
+		t.Fatalf("frame length %q: %v", length, err)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	return string(msg)
+}
+
+// syslogFields splits a message into PRI+VERSION, TIMESTAMP, HOSTNAME,
+// APP-NAME, PROCID, MSGID and the rest.
+func syslogFields(t *testing.T, msg string) []string {
+	t.Helper()
+	fields := strings.SplitN(msg, " ", 7)
+	if len(fields) != 7 {
+		t.Fatalf("malformed syslog message %q", msg)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, fields[1]); err != nil {
+		t.Fatalf("timestamp %q: %v", fields[1], err)
+	}
+	return fields
+}
+
+func TestSyslogWriterTCPStructuredData(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	w, err := NewSyslogWriter("tcp", ln.Addr().String(), SyslogOptions{Facility: SyslogLocal0, AppName: "api", Hostname: "host one"})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	logger := NewWithOptions(context.Background(), w, Options{Mode: ModeStructured, NoColor: true}).With("svc", "api")
+	logger.Warn("slow request", "http", map[string]any{"status": 503}, "note", `a "quoted" ] value`)
+	logger.Trace("hidden")
+	logger.Error("failed", "tags", []string{"a", "b"})
+
+	conn := <-accepted
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	first := syslogFields(t, readOctetCounted(t, r))
+	if first[0] != "<132>1" {
+		t.Fatalf("expected local0.warning, got %q", first[0])
+	}
+	if first[2] != "host_one" || first[3] != "api" || first[5] != "-" {
+		t.Fatalf("unexpected header %q", first[:6])
+	}
+	want := `[pslog@32473 svc="api" http.status="503" note="a \"quoted\" \] value"] slow request`
+	if first[6] != want {
+		t.Fatalf("unexpected structured data\nwant %s\ngot  %s", want, first[6])
+	}
+	second := syslogFields(t, readOctetCounted(t, r))
+	if second[0] != "<131>1" || second[6] != `[pslog@32473 svc="api" tags="[\"a\",\"b\"\]"] failed` {
+		t.Fatalf("unexpected second message %q", second)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := w.Write([]byte("{}\n")); err == nil {
+		t.Fatalf("expected write after close to fail")
+	}
+}
+
+func TestSyslogWriterUDPJSON(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer pc.Close()
+	w, err := NewSyslogWriter("udp", pc.LocalAddr().String(), SyslogOptions{Format: SyslogJSON, MsgID: "audit"})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte("{\"lvl\":\"trace\",\"msg\":\"a\"}\nnot json\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	buf := make([]byte, 2048)
+	var got []string
+	for range 2 {
+		_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		got = append(got, string(buf[:n]))
+	}
+	first := syslogFields(t, got[0])
+	if first[0] != "<15>1" || first[5] != "audit" || first[6] != `- {"lvl":"trace","msg":"a"}` {
+		t.Fatalf("unexpected JSON message %q", got[0])
+	}
+	if second := syslogFields(t, got[1]); second[0] != "<14>1" || second[6] != "- not json" {
+		t.Fatalf("unexpected plain message %q", got[1])
+	}
+}
+
+func TestParseStructuredLineKeepsUserKeysAsFields(t *testing.T) {
+	for _, verbose := range []bool{false, true} {
+		var buf strings.Builder
+		logger := NewWithOptions(context.Background(), &buf, Options{Mode: ModeStructured, NoColor: true, VerboseFields: verbose})
+		logger.Warn("login", "time", "noon", "message", "from user", "level", "admin", "ts", 1, "lvl", "x", "msg", "y")
+		entry, ok := parseStructuredLine([]byte(strings.TrimSpace(buf.String())), true)
+		if !ok || entry.level != WarnLevel || entry.msg != "login" {
+			t.Fatalf("verbose=%v: unexpected entry %+v", verbose, entry)
+		}
+		var got []string
+		for _, field := range entry.fields {
+			got = append(got, field.name+"="+field.value)
+		}
+		if want := "time=noon,message=from user,level=admin,ts=1,lvl=x,msg=y"; strings.Join(got, ",") != want {
+			t.Fatalf("verbose=%v: fields %q, want %q", verbose, strings.Join(got, ","), want)
+		}
+	}
+}
+
+func TestSyslogWriterReconnects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	conns := make(chan net.Conn, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+	w, err := NewSyslogWriter("tcp", ln.Addr().String(), SyslogOptions{ReconnectInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer w.Close()
+	first := <-conns
+	_ = first.Close()
+
+	// Reconnects happen in the background and drop lines until they finish,
+	// so keep writing until the new connection delivers one.
+	write := func() { _, _ = w.Write([]byte(`{"lvl":"info","msg":"again"}` + "\n")) }
+	deadline := time.Now().Add(5 * time.Second)
+	var conn net.Conn
+	for conn == nil {
+		write()
+		select {
+		case conn = <-conns:
+		case <-time.After(10 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("writer did not reconnect")
+		}
+	}
+	defer conn.Close()
+	received := make(chan string, 1)
+	go func() {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		received <- readOctetCounted(t, bufio.NewReader(conn))
+	}()
+	for {
+		select {
+		case raw := <-received:
+			if msg := syslogFields(t, raw); msg[6] != "- again" {
+				t.Fatalf("unexpected message after reconnect %q", msg)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+			write()
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no message after reconnect")
+		}
+	}
+}
+
+func TestSyslogWriterDoesNotHoldLockWhileDialling(t *testing.T) {
+	w := &SyslogWriter{
+		network: "tcp",
+		// 192.0.2.0/24 is TEST-NET-1, so the dial hangs until DialTimeout.
+		address: "192.0.2.1:514",
+		opts:    SyslogOptions{Facility: SyslogUser, DialTimeout: time.Second, WriteTimeout: time.Second, ReconnectInterval: time.Millisecond},
+	}
+	start := time.Now()
+	for range 3 {
+		if _, err := w.Write([]byte(`{"msg":"dropped"}` + "\n")); err == nil {
+			t.Fatal("expected lines to be dropped while disconnected")
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Write waited %v for the dial", elapsed)
+	}
+	_ = w.Close()
+}
+
+func TestSyslogWriterUnixDatagram(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("unix datagram sockets")
+	}
+	path := filepath.Join(t.TempDir(), "log.sock")
+	pc, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skipf("unixgram: %v", err)
+	}
+	defer pc.Close()
+	w, err := NewSyslogWriter("unix", path, SyslogOptions{})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte(`{"lvl":"panic","msg":"boom"}` + "\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if msg := syslogFields(t, string(buf[:n])); msg[0] != "<9>1" || msg[6] != "- boom" {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestLoggerFromEnvSyslogOutput(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+	t.Setenv("LOG_OUTPUT", "syslog+tcp://"+ln.Addr().String()+"?facility=daemon&app=worker")
+	t.Setenv("LOG_MODE", "console")
+	t.Setenv("LOG_FORCE_COLOR", "true")
+	logger := LoggerFromEnv(context.Background())
+	logger.Info("started", "jobs", 3)
+
+	conn := <-accepted
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	msg := syslogFields(t, readOctetCounted(t, bufio.NewReader(conn)))
+	if msg[0] != "<30>1" || msg[3] != "worker" || msg[6] != `[pslog@32473 jobs="3"] started` {
+		t.Fatalf("unexpected message %q", msg)
+	}
+	if err := Close(logger); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+func TestOpenSyslogOutputRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{
+		"syslog+tcp://",
+		"syslog+unix://",
+		"syslog+quic://host:514",
+		"syslog+udp://127.0.0.1:514?facility=nope",
+		"syslog+udp://127.0.0.1:514?format=xml",
+		"syslog+udp://127.0.0.1:514?colour=1",
+	} {
+		if w, err := openSyslogOutput(spec); err == nil {
+			_ = w.Close()
+			t.Fatalf("expected %q to fail", spec)
+		}
+	}
+}
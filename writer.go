@@ -26,6 +26,16 @@ type lineWriter struct {
 	stringCache   [stringCacheSlots]literalCacheEntry
 	nullLiteral   literalCacheEntry
 	floatPolicy   NonFiniteFloatPolicy
+	enc           ObjectEncoder
+	// keyvals is scratch space for runtime keyvals extended with the caller.
+	keyvals []any
+	// caller holds the caller pair of grouped loggers, which is written at
+	// the top level rather than with the runtime keyvals.
+	caller []any
+	// origin carries the time and caller of a log/slog record, if any.
+	origin *recordOrigin
+	// entry is the hook entry being written on the cold path, if any.
+	entry *Entry
 }
 
 const (
@@ -85,6 +95,12 @@ func releaseLineWriter(lw *lineWriter) {
 	lw.autoFlush = true
 	lw.lastLen = 0
 	lw.floatPolicy = NonFiniteFloatAsString
+	clear(lw.keyvals)
+	lw.keyvals = lw.keyvals[:0]
+	clear(lw.caller)
+	lw.caller = lw.caller[:0]
+	lw.origin = nil
+	lw.entry = nil
 	lineWriterPool.Put(lw)
 }
 